@@ -92,6 +92,9 @@ type indexSpec struct {
 	index     index
 	columns   []model.ColumnKey
 	indexType indexType
+	// ordered flags if the rows are also kept sorted by the value of the
+	// single column of the index
+	ordered bool
 }
 
 func (s indexSpec) isClientIndex() bool {
@@ -148,7 +151,80 @@ type RowCache struct {
 	cache      map[string]model.Model
 	indexSpecs []indexSpec
 	indexes    columnToValue
-	mutex      sync.RWMutex
+	// ordered holds the rows sorted for the ordered indexes
+	ordered map[index]orderedIndex
+	// referenceSpecs are the columns referencing other tables, and
+	// references their reverse index
+	referenceSpecs []referenceSpec
+	references     map[referenceSpec]referenceIndex
+	// shared is set when the rows and indexes are shared with a snapshot,
+	// in which case they are copied before being modified
+	shared bool
+	mutex  sync.RWMutex
+}
+
+// unshare copies the rows and indexes shared with a snapshot so that they can
+// be modified. Caller must hold the row cache lock.
+func (r *RowCache) unshare() {
+	if !r.shared {
+		return
+	}
+	rows := make(map[string]model.Model, len(r.cache))
+	for uuid, row := range r.cache {
+		// rows are never modified in place, just replaced
+		rows[uuid] = row
+	}
+	indexes := make(columnToValue, len(r.indexes))
+	for index, values := range r.indexes {
+		indexes[index] = make(valueToUUIDs, len(values))
+		for value, uuids := range values {
+			copied := make(uuidset, len(uuids))
+			for uuid := range uuids {
+				copied.add(uuid)
+			}
+			indexes[index][value] = copied
+		}
+	}
+	ordered := make(map[index]orderedIndex, len(r.ordered))
+	for index, entries := range r.ordered {
+		ordered[index] = append(make(orderedIndex, 0, len(entries)), entries...)
+	}
+	references := make(map[referenceSpec]referenceIndex, len(r.references))
+	for spec, index := range r.references {
+		references[spec] = make(referenceIndex, len(index))
+		for to, from := range index {
+			copied := make(uuidset, len(from))
+			for uuid := range from {
+				copied.add(uuid)
+			}
+			references[spec][to] = copied
+		}
+	}
+	r.cache = rows
+	r.indexes = indexes
+	r.ordered = ordered
+	r.references = references
+	r.shared = false
+}
+
+// snapshot returns a RowCache sharing the rows and indexes of this one until
+// either of them is modified
+func (r *RowCache) snapshot() *RowCache {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.shared = true
+	return &RowCache{
+		name:           r.name,
+		dbModel:        r.dbModel,
+		dataType:       r.dataType,
+		cache:          r.cache,
+		indexSpecs:     r.indexSpecs,
+		indexes:        r.indexes,
+		ordered:        r.ordered,
+		referenceSpecs: r.referenceSpecs,
+		references:     r.references,
+		shared:         true,
+	}
 }
 
 // rowByUUID returns one model from the cache by UUID. Caller must hold the row
@@ -261,6 +337,7 @@ func (r *RowCache) RowsByModels(models []model.Model) (map[string]model.Model, e
 func (r *RowCache) Create(uuid string, m model.Model, checkIndexes bool) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	r.unshare()
 	if _, ok := r.cache[uuid]; ok {
 		return NewErrCacheInconsistent(fmt.Sprintf("cannot create row %s as it already exists", uuid))
 	}
@@ -302,6 +379,12 @@ func (r *RowCache) Create(uuid string, m model.Model, checkIndexes bool) error {
 		}
 	}
 
+	if err := r.updateOrderedIndexes(uuid, nil, info); err != nil {
+		return err
+	}
+
+	r.addReferences(uuid, r.rowReferences(info))
+
 	r.cache[uuid] = model.Clone(m)
 	return nil
 }
@@ -310,6 +393,7 @@ func (r *RowCache) Create(uuid string, m model.Model, checkIndexes bool) error {
 func (r *RowCache) Update(uuid string, m model.Model, checkIndexes bool) (model.Model, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	r.unshare()
 	if _, ok := r.cache[uuid]; !ok {
 		return nil, NewErrCacheInconsistent(fmt.Sprintf("cannot update row %s as it does not exist in the cache", uuid))
 	}
@@ -383,6 +467,13 @@ func (r *RowCache) Update(uuid string, m model.Model, checkIndexes bool) (model.
 		}
 	}
 
+	if err := r.updateOrderedIndexes(uuid, oldInfo, newInfo); err != nil {
+		return nil, err
+	}
+
+	r.removeReferences(uuid, r.rowReferences(oldInfo))
+	r.addReferences(uuid, r.rowReferences(newInfo))
+
 	r.cache[uuid] = model.Clone(m)
 	return oldRow, nil
 }
@@ -429,6 +520,7 @@ func (r *RowCache) IndexExists(row model.Model) error {
 func (r *RowCache) Delete(uuid string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	r.unshare()
 	if _, ok := r.cache[uuid]; !ok {
 		return NewErrCacheInconsistent(fmt.Sprintf("cannot delete row %s as it does not exist in the cache", uuid))
 	}
@@ -462,6 +554,12 @@ func (r *RowCache) Delete(uuid string) error {
 		}
 	}
 
+	if err := r.updateOrderedIndexes(uuid, oldInfo, nil); err != nil {
+		return err
+	}
+
+	r.removeReferences(uuid, r.rowReferences(oldInfo))
+
 	delete(r.cache, uuid)
 	return nil
 }
@@ -694,6 +792,20 @@ func (r *RowCache) RowsByCondition(conditions []ovsdb.Condition) (map[string]mod
 		return nil, err
 	}
 
+	// further reduce the matches with the range conditions that can be
+	// evaluated through ordered indexes
+	for i, condition := range conditions {
+		uuids := r.uuidsByRangeCondition(condition, nativeValues[i])
+		if uuids == nil {
+			continue
+		}
+		if matching == nil {
+			matching = uuids
+		} else if matching = intersectUUIDSets(matching, uuids); matching == nil {
+			matching = uuidset{}
+		}
+	}
+
 	// From the matches obtained with indexes, which might have not used all
 	// conditions, continue trimming down the list explicitly evaluating the
 	// conditions.
@@ -802,6 +914,7 @@ type EventHandlerFuncs struct {
 	AddFunc    func(table string, model model.Model)
 	UpdateFunc func(table string, old model.Model, new model.Model)
 	DeleteFunc func(table string, model model.Model)
+	ResyncFunc func()
 }
 
 // OnAdd calls AddFunc if it is not nil
@@ -825,6 +938,18 @@ func (e *EventHandlerFuncs) OnDelete(table string, row model.Model) {
 	}
 }
 
+// OnResync calls ResyncFunc if it is not nil. Events are only dropped in
+// favor of a resync if it is not nil
+func (e *EventHandlerFuncs) OnResync() {
+	if e.ResyncFunc != nil {
+		e.ResyncFunc()
+	}
+}
+
+func (e *EventHandlerFuncs) handlesResync() bool {
+	return e.ResyncFunc != nil
+}
+
 // TableCache contains a collection of RowCaches, hashed by name,
 // and an array of EventHandlers that respond to cache updates
 // It implements the ovsdb.NotificationHandler interface so it may
@@ -834,8 +959,12 @@ type TableCache struct {
 	eventProcessor *eventProcessor
 	dbModel        model.DatabaseModel
 	ovsdb.NotificationHandler
-	mutex  sync.RWMutex
-	logger *logr.Logger
+	mutex sync.RWMutex
+	// updateMutex serializes the updates of the cache together with the
+	// delivery of their events, which happens once the cache is unlocked
+	// so that handlers can read from it
+	updateMutex sync.Mutex
+	logger      *logr.Logger
 }
 
 // Data is the type for data that can be prepopulated in the cache
@@ -899,6 +1028,27 @@ func (t *TableCache) Table(name string) *RowCache {
 	return nil
 }
 
+// Snapshot returns a TableCache holding the rows of all the tables as of the
+// last update fully applied to the cache. The rows are shared with the cache
+// until they are modified, so taking a snapshot does not hold up the
+// processing of updates, which do not affect the snapshot. The snapshot is
+// meant to be read from: no events are delivered for it.
+func (t *TableCache) Snapshot() *TableCache {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	cache := make(map[string]*RowCache, len(t.cache))
+	for name, rowCache := range t.cache {
+		cache[name] = rowCache.snapshot()
+	}
+	return &TableCache{
+		cache:          cache,
+		eventProcessor: newEventProcessor(0, t.logger),
+		dbModel:        t.dbModel,
+		mutex:          sync.RWMutex{},
+		logger:         t.logger,
+	}
+}
+
 // Tables returns a list of table names that are in the cache
 func (t *TableCache) Tables() []string {
 	t.mutex.RLock()
@@ -956,9 +1106,18 @@ func (t *TableCache) Disconnected() {
 
 // Populate adds data to the cache and places an event on the channel
 func (t *TableCache) Populate(tableUpdates ovsdb.TableUpdates) error {
+	t.updateMutex.Lock()
+	defer t.updateMutex.Unlock()
+	events, err := t.populate(tableUpdates)
+	t.eventProcessor.AddEvents(events)
+	return err
+}
+
+func (t *TableCache) populate(tableUpdates ovsdb.TableUpdates) ([]event, error) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
+	var events []event
 	for table := range t.dbModel.Types() {
 		tu, ok := tableUpdates[table]
 		if !ok {
@@ -971,21 +1130,30 @@ func (t *TableCache) Populate(tableUpdates ovsdb.TableUpdates) error {
 			current := tCache.cache[uuid]
 			err := update.AddRowUpdate(t.dbModel, table, uuid, current, *row)
 			if err != nil {
-				return err
+				return events, err
 			}
-			err = t.ApplyCacheUpdate(update)
+			events, err = t.applyCacheUpdate(update, events)
 			if err != nil {
-				return err
+				return events, err
 			}
 		}
 	}
-	return nil
+	return events, nil
 }
 
 // Populate2 adds data to the cache and places an event on the channel
 func (t *TableCache) Populate2(tableUpdates ovsdb.TableUpdates2) error {
+	t.updateMutex.Lock()
+	defer t.updateMutex.Unlock()
+	events, err := t.populate2(tableUpdates)
+	t.eventProcessor.AddEvents(events)
+	return err
+}
+
+func (t *TableCache) populate2(tableUpdates ovsdb.TableUpdates2) ([]event, error) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
+	var events []event
 	for table := range t.dbModel.Types() {
 		tu, ok := tableUpdates[table]
 		if !ok {
@@ -997,19 +1165,19 @@ func (t *TableCache) Populate2(tableUpdates ovsdb.TableUpdates2) error {
 			update := updates.ModelUpdates{}
 			current := tCache.cache[uuid]
 			if row.Initial == nil && row.Insert == nil && current == nil {
-				return NewErrCacheInconsistent(fmt.Sprintf("row with uuid %s does not exist", uuid))
+				return events, NewErrCacheInconsistent(fmt.Sprintf("row with uuid %s does not exist", uuid))
 			}
 			err := update.AddRowUpdate2(t.dbModel, table, uuid, current, *row)
 			if err != nil {
-				return err
+				return events, err
 			}
-			err = t.ApplyCacheUpdate(update)
+			events, err = t.applyCacheUpdate(update, events)
 			if err != nil {
-				return err
+				return events, err
 			}
 		}
 	}
-	return nil
+	return events, nil
 }
 
 // Purge drops all data in the cache and reinitializes it using the
@@ -1024,9 +1192,24 @@ func (t *TableCache) Purge(dbModel model.DatabaseModel) {
 	}
 }
 
-// AddEventHandler registers the supplied EventHandler to receive cache events
-func (t *TableCache) AddEventHandler(handler EventHandler) {
-	t.eventProcessor.AddEventHandler(handler)
+// AddEventHandler registers the supplied EventHandler to receive cache events.
+// By default, events that do not fit in the buffer of the handler are dropped;
+// the options change how they are delivered.
+func (t *TableCache) AddEventHandler(handler EventHandler, opts ...EventHandlerOption) {
+	t.eventProcessor.AddEventHandler(handler, opts...)
+}
+
+// HoldEvents makes the events of the cache buffered for the handlers with
+// DeliveryBlock without waiting for them to fit in their buffers, until
+// ReleaseEvents is called. It lets the cache be updated while holding a lock
+// that the handlers might need to read it.
+func (t *TableCache) HoldEvents() {
+	t.eventProcessor.hold()
+}
+
+// ReleaseEvents undoes HoldEvents
+func (t *TableCache) ReleaseEvents() {
+	t.eventProcessor.release()
 }
 
 // Run starts the event processing and update processing loops.
@@ -1070,16 +1253,30 @@ func newRowCache(name string, dbModel model.DatabaseModel, dataType reflect.Type
 	for _, clientIndex := range clientIndexes {
 		columnKeys := clientIndex.Columns
 		index := newIndexFromColumnKeys(columnKeys...)
-		// if this is already a DB index, ignore
+		ordered := clientIndex.Type == model.OrderedIndexType
+		// if this is already a DB index, ignore, other than keeping it
+		// ordered if requested
 		if _, ok := indexes[index]; ok {
+			for i := range r.indexSpecs {
+				if r.indexSpecs[i].index == index {
+					r.indexSpecs[i].ordered = r.indexSpecs[i].ordered || ordered
+				}
+			}
 			continue
 		}
-		spec := indexSpec{index: index, columns: columnKeys, indexType: clientIndexType}
+		spec := indexSpec{index: index, columns: columnKeys, indexType: clientIndexType, ordered: ordered}
 		r.indexSpecs = append(r.indexSpecs, spec)
 		indexes[index] = spec
 	}
 
 	r.indexes = r.newIndexes()
+	r.ordered = map[index]orderedIndex{}
+
+	r.referenceSpecs = newReferenceSpecs(dbModel.Schema.Table(name))
+	r.references = make(map[referenceSpec]referenceIndex, len(r.referenceSpecs))
+	for _, spec := range r.referenceSpecs {
+		r.references[spec] = referenceIndex{}
+	}
 	return r
 }
 
@@ -1092,94 +1289,27 @@ func (r *RowCache) newIndexes() columnToValue {
 	return c
 }
 
-// event encapsulates a cache event
-type event struct {
-	eventType string
-	table     string
-	old       model.Model
-	new       model.Model
-}
-
-// eventProcessor handles the queueing and processing of cache events
-type eventProcessor struct {
-	events chan *event
-	// handlersMutex locks the handlers array when we add a handler or dispatch events
-	// we don't need a RWMutex in this case as we only have one thread reading and the write
-	// volume is very low (i.e only when AddEventHandler is called)
-	handlersMutex sync.Mutex
-	handlers      []EventHandler
-	logger        *logr.Logger
-}
-
-func newEventProcessor(capacity int, logger *logr.Logger) *eventProcessor {
-	return &eventProcessor{
-		events:   make(chan *event, capacity),
-		handlers: []EventHandler{},
-		logger:   logger,
-	}
-}
-
-// AddEventHandler registers the supplied EventHandler with the eventProcessor
-// EventHandlers MUST process events quickly, for example, pushing them to a queue
-// to be processed by the client. Long Running handler functions adversely affect
-// other handlers and MAY cause loss of data if the channel buffer is full
-func (e *eventProcessor) AddEventHandler(handler EventHandler) {
-	e.handlersMutex.Lock()
-	defer e.handlersMutex.Unlock()
-	e.handlers = append(e.handlers, handler)
-}
-
-// AddEvent writes an event to the channel
-func (e *eventProcessor) AddEvent(eventType string, table string, old model.Model, new model.Model) {
-	// We don't need to check for error here since there
-	// is only a single writer. RPC is run in blocking mode
-	event := event{
-		eventType: eventType,
-		table:     table,
-		old:       old,
-		new:       new,
-	}
-	select {
-	case e.events <- &event:
-		// noop
-		return
-	default:
-		e.logger.V(0).Info("dropping event because event buffer is full")
-	}
-}
-
-// Run runs the eventProcessor loop.
-// It will block until the stopCh has been closed
-// Otherwise it will wait for events to arrive on the event channel
-// Once received, it will dispatch the event to each registered handler
-func (e *eventProcessor) Run(stopCh <-chan struct{}) {
-	for {
-		select {
-		case <-stopCh:
-			return
-		case event := <-e.events:
-			e.handlersMutex.Lock()
-			for _, handler := range e.handlers {
-				switch event.eventType {
-				case addEvent:
-					handler.OnAdd(event.table, event.new)
-				case updateEvent:
-					handler.OnUpdate(event.table, event.old, event.new)
-				case deleteEvent:
-					handler.OnDelete(event.table, event.old)
-				}
-			}
-			e.handlersMutex.Unlock()
-		}
-	}
-}
-
 type cacheUpdate interface {
 	GetUpdatedTables() []string
 	ForEachModelUpdate(table string, do func(uuid string, old, new model.Model) error) error
 }
 
+// ApplyCacheUpdate applies the changes of an update to the cache and
+// places the corresponding events on the channel
 func (t *TableCache) ApplyCacheUpdate(update cacheUpdate) error {
+	t.updateMutex.Lock()
+	defer t.updateMutex.Unlock()
+	t.mutex.Lock()
+	events, err := t.applyCacheUpdate(update, nil)
+	t.mutex.Unlock()
+	t.eventProcessor.AddEvents(events)
+	return err
+}
+
+// applyCacheUpdate applies the changes of an update to the cache, appending
+// the corresponding events to the provided ones. Caller must hold the cache
+// lock.
+func (t *TableCache) applyCacheUpdate(update cacheUpdate, events []event) ([]event, error) {
 	tables := update.GetUpdatedTables()
 	for _, table := range tables {
 		tCache := t.cache[table]
@@ -1191,29 +1321,29 @@ func (t *TableCache) ApplyCacheUpdate(update cacheUpdate) error {
 				if err != nil {
 					return err
 				}
-				t.eventProcessor.AddEvent(addEvent, table, nil, new)
+				events = append(events, event{eventType: addEvent, table: table, uuid: uuid, new: new})
 			case old != nil && new != nil:
 				t.logger.V(5).Info("updating model", "table", table, "uuid", uuid, "old", old, "new", new)
 				_, err := tCache.Update(uuid, new, false)
 				if err != nil {
 					return err
 				}
-				t.eventProcessor.AddEvent(updateEvent, table, old, new)
+				events = append(events, event{eventType: updateEvent, table: table, uuid: uuid, old: old, new: new})
 			case new == nil:
 				t.logger.V(5).Info("deleting model", "table", table, "uuid", uuid, "model", old)
 				err := tCache.Delete(uuid)
 				if err != nil {
 					return err
 				}
-				t.eventProcessor.AddEvent(deleteEvent, table, old, nil)
+				events = append(events, event{eventType: deleteEvent, table: table, uuid: uuid, old: old})
 			}
 			return nil
 		})
 		if err != nil {
-			return err
+			return events, err
 		}
 	}
-	return nil
+	return events, nil
 }
 
 func valueFromIndex(info *mapper.Info, columnKeys []model.ColumnKey) (interface{}, error) {
@@ -2029,35 +2029,103 @@ func TestTableCachePopulate2BrokenIndexes(t *testing.T) {
 	require.NotNil(t, result)
 }
 
+func TestTableCacheSnapshot(t *testing.T) {
+	db, err := model.NewClientDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal(getTestSchema(`["foo"]`), &schema)
+	assert.Nil(t, err)
+	dbModel, errs := model.NewDatabaseModel(schema, db)
+	require.Empty(t, errs)
+	tc, err := NewTableCache(dbModel, Data{
+		"Open_vSwitch": {
+			"test1": &testModel{UUID: "test1", Foo: "bar"},
+			"test2": &testModel{UUID: "test2", Foo: "baz"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	snapshot := tc.Snapshot()
+	expected := map[string]model.Model{
+		"test1": &testModel{UUID: "test1", Foo: "bar"},
+		"test2": &testModel{UUID: "test2", Foo: "baz"},
+	}
+	assert.Equal(t, expected, snapshot.Table("Open_vSwitch").Rows())
+
+	t.Log("Updates To The Cache")
+	modifiedRow := ovsdb.Row(map[string]interface{}{"foo": "quux"})
+	deletedRow := ovsdb.Row(map[string]interface{}{"_uuid": "test2", "foo": "baz"})
+	insertedRow := ovsdb.Row(map[string]interface{}{"_uuid": "test3", "foo": "bar"})
+	err = tc.Populate2(ovsdb.TableUpdates2{
+		"Open_vSwitch": {
+			"test1": &ovsdb.RowUpdate2{Modify: &modifiedRow},
+			"test2": &ovsdb.RowUpdate2{Delete: &deletedRow},
+		},
+	})
+	require.NoError(t, err)
+	err = tc.Populate2(ovsdb.TableUpdates2{
+		"Open_vSwitch": {
+			"test3": &ovsdb.RowUpdate2{Insert: &insertedRow},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.Model{
+		"test1": &testModel{UUID: "test1", Foo: "quux"},
+		"test3": &testModel{UUID: "test3", Foo: "bar"},
+	}, tc.Table("Open_vSwitch").Rows())
+
+	t.Log("Snapshot Is Unchanged")
+	assert.Equal(t, expected, snapshot.Table("Open_vSwitch").Rows())
+	uuid, _, err := snapshot.Table("Open_vSwitch").RowByModel(&testModel{Foo: "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, "test1", uuid)
+	uuid, _, err = snapshot.Table("Open_vSwitch").RowByModel(&testModel{Foo: "baz"})
+	require.NoError(t, err)
+	assert.Equal(t, "test2", uuid)
+	uuid, _, err = tc.Table("Open_vSwitch").RowByModel(&testModel{Foo: "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, "test3", uuid)
+
+	t.Log("Updates To The Snapshot")
+	err = snapshot.Table("Open_vSwitch").Delete("test1")
+	require.NoError(t, err)
+	assert.False(t, snapshot.Table("Open_vSwitch").HasRow("test1"))
+	assert.True(t, tc.Table("Open_vSwitch").HasRow("test1"))
+}
+
 func TestEventProcessor_AddEvent(t *testing.T) {
 	logger := logr.Discard()
 	ep := newEventProcessor(16, &logger)
+	ep.AddEventHandler(&EventHandlerFuncs{})
+	q := ep.handlers[0]
 	var events []event
 	for i := 0; i < 17; i++ {
 		events = append(events, event{
 			table:     "bridge",
 			eventType: addEvent,
+			uuid:      "unique",
 			new: &testModel{
 				UUID: "unique",
 				Foo:  "bar",
 			},
 		})
 	}
-	// overfill channel so event 16 is dropped
+	// overfill buffer so event 16 is dropped
 	for _, e := range events {
-		ep.AddEvent(e.eventType, e.table, nil, e.new)
+		ep.AddEvent(e.eventType, e.table, e.uuid, nil, e.new)
 	}
-	// assert channel is full of events
-	assert.Equal(t, 16, len(ep.events))
+	// assert buffer is full of events
+	assert.Equal(t, 16, len(q.events))
 
 	// read events and ensure they are in FIFO order
 	for i := 0; i < 16; i++ {
-		event := <-ep.events
+		event, resync := q.next(nil)
+		assert.False(t, resync)
 		assert.Equal(t, &testModel{UUID: "unique", Foo: "bar"}, event.new)
 	}
 
-	// assert channel is empty
-	assert.Equal(t, 0, len(ep.events))
+	// assert buffer is empty
+	assert.Equal(t, 0, len(q.events))
 }
 
 func TestIndex(t *testing.T) {
@@ -12,5 +12,16 @@ update notifications
 It also contains an eventProcessor where callers
 may registers functions that will get called on
 every Add/Update/Delete event.
+
+Each handler has its own buffer of events. By default,
+the events that do not fit in it are dropped, and a
+ResyncHandler is notified with OnResync so that it can
+rebuild its state from the cache. Handlers can instead
+block the processing of updates or buffer their events
+without limit, and have the events of a row merged:
+
+    cache.AddEventHandler(handler,
+        cache.WithDeliveryMode(cache.DeliveryBlock),
+        cache.WithCoalescing())
 */
 package cache
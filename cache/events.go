@@ -0,0 +1,416 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/ovn-org/libovsdb/model"
+)
+
+// DeliveryMode defines what happens to the events of an EventHandler that
+// does not keep up with them
+type DeliveryMode int
+
+const (
+	// DeliveryDrop drops the events that do not fit in the buffer of the
+	// handler. A ResyncHandler is then notified with OnResync.
+	DeliveryDrop DeliveryMode = iota
+	// DeliveryBlock holds up the processing of updates until the events fit
+	// in the buffer of the handler. Events are buffered without limit while
+	// the cache is not running, e.g. while the client reconnects, and while
+	// its events are held, see TableCache.HoldEvents.
+	DeliveryBlock
+	// DeliveryUnbounded grows the buffer of the handler to hold all its
+	// events
+	DeliveryUnbounded
+)
+
+// ResyncHandler is an EventHandler notified when some of its events were
+// dropped. OnResync is called in place of the events that were buffered when
+// the first one was dropped; the handler is expected to rebuild its state
+// from the cache, which might already reflect the events delivered next.
+type ResyncHandler interface {
+	EventHandler
+	OnResync()
+}
+
+// EventHandlerOption configures the delivery of events to an EventHandler
+type EventHandlerOption func(*handlerQueue)
+
+// WithDeliveryMode sets what happens to the events of the handler when it
+// does not keep up with them. It defaults to DeliveryDrop.
+func WithDeliveryMode(mode DeliveryMode) EventHandlerOption {
+	return func(q *handlerQueue) {
+		q.mode = mode
+	}
+}
+
+// WithBufferSize sets the number of events buffered for the handler
+func WithBufferSize(size int) EventHandlerOption {
+	return func(q *handlerQueue) {
+		q.size = size
+	}
+}
+
+// WithCoalescing merges the events of a row buffered for the handler into a
+// single one: e.g. an update following an add is delivered as an add of the
+// updated row, while a delete following an add cancels both
+func WithCoalescing() EventHandlerOption {
+	return func(q *handlerQueue) {
+		q.coalesce = true
+	}
+}
+
+// optionalResyncHandler is implemented by the ResyncHandlers that might not
+// handle resyncs, like EventHandlerFuncs without a ResyncFunc
+type optionalResyncHandler interface {
+	handlesResync() bool
+}
+
+// asResyncHandler returns the handler as a ResyncHandler, or nil if it does
+// not handle resyncs
+func asResyncHandler(handler EventHandler) ResyncHandler {
+	if optional, ok := handler.(optionalResyncHandler); ok && !optional.handlesResync() {
+		return nil
+	}
+	resyncHandler, _ := handler.(ResyncHandler)
+	return resyncHandler
+}
+
+// TypedEventHandlerFuncs is an EventHandler for the models of type T, which
+// ignores the events of the other tables. It allows a caller to only
+// implement the functions they need
+type TypedEventHandlerFuncs[T any] struct {
+	AddFunc    func(model *T)
+	UpdateFunc func(old *T, new *T)
+	DeleteFunc func(model *T)
+	ResyncFunc func()
+}
+
+// OnAdd calls AddFunc if it is not nil and the model is a T
+func (e *TypedEventHandlerFuncs[T]) OnAdd(table string, m model.Model) {
+	if typed, ok := m.(*T); ok && e.AddFunc != nil {
+		e.AddFunc(typed)
+	}
+}
+
+// OnUpdate calls UpdateFunc if it is not nil and the models are T
+func (e *TypedEventHandlerFuncs[T]) OnUpdate(table string, old, new model.Model) {
+	typedOld, ok := old.(*T)
+	if !ok || e.UpdateFunc == nil {
+		return
+	}
+	e.UpdateFunc(typedOld, new.(*T))
+}
+
+// OnDelete calls DeleteFunc if it is not nil and the model is a T
+func (e *TypedEventHandlerFuncs[T]) OnDelete(table string, m model.Model) {
+	if typed, ok := m.(*T); ok && e.DeleteFunc != nil {
+		e.DeleteFunc(typed)
+	}
+}
+
+// OnResync calls ResyncFunc if it is not nil
+func (e *TypedEventHandlerFuncs[T]) OnResync() {
+	if e.ResyncFunc != nil {
+		e.ResyncFunc()
+	}
+}
+
+func (e *TypedEventHandlerFuncs[T]) handlesResync() bool {
+	return e.ResyncFunc != nil
+}
+
+// handlerQueue buffers the events of an EventHandler and delivers them
+type handlerQueue struct {
+	handler  EventHandler
+	resync   ResyncHandler
+	mode     DeliveryMode
+	size     int
+	coalesce bool
+	logger   *logr.Logger
+
+	mutex sync.Mutex
+	// cond is signaled when events are buffered or delivered, and when
+	// delivery starts or stops
+	cond    *sync.Cond
+	events  []*event
+	running bool
+	// lost is set when events were dropped and the handler has to resync
+	lost bool
+	// rows holds the buffered events by table and row when coalescing
+	rows map[string]map[string]*event
+}
+
+func newHandlerQueue(handler EventHandler, size int, logger *logr.Logger, opts ...EventHandlerOption) *handlerQueue {
+	q := &handlerQueue{
+		handler: handler,
+		resync:  asResyncHandler(handler),
+		mode:    DeliveryDrop,
+		size:    size,
+		logger:  logger,
+		rows:    map[string]map[string]*event{},
+	}
+	q.cond = sync.NewCond(&q.mutex)
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// add buffers an event for the handler. With DeliveryBlock, it waits for the
+// event to fit in the buffer unless held is set.
+func (q *handlerQueue) add(e event, held bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.coalesce && q.coalesceEvent(e) {
+		return
+	}
+	switch q.mode {
+	case DeliveryBlock:
+		for !held && q.running && len(q.events) >= q.size {
+			q.cond.Wait()
+		}
+	case DeliveryDrop:
+		if len(q.events) >= q.size {
+			q.logger.V(0).Info("dropping event because event buffer is full", "table", e.table, "uuid", e.uuid)
+			if q.resync != nil && !q.lost {
+				// the buffered events are superseded by the resync
+				q.events = nil
+				q.rows = map[string]map[string]*event{}
+				q.lost = true
+			}
+			return
+		}
+	}
+	queued := e
+	q.events = append(q.events, &queued)
+	if q.coalesce && e.uuid != "" {
+		if q.rows[e.table] == nil {
+			q.rows[e.table] = map[string]*event{}
+		}
+		q.rows[e.table][e.uuid] = &queued
+	}
+	q.cond.Broadcast()
+}
+
+// coalesceEvent merges an event into the one buffered for the same row, if
+// any. Caller must hold the queue lock.
+func (q *handlerQueue) coalesceEvent(e event) bool {
+	queued, ok := q.rows[e.table][e.uuid]
+	if !ok || e.uuid == "" {
+		return false
+	}
+	switch {
+	case queued.eventType == addEvent && e.eventType == updateEvent:
+		queued.new = e.new
+	case queued.eventType == addEvent && e.eventType == deleteEvent:
+		// the handler never knew about the row
+		q.remove(queued)
+	case queued.eventType == updateEvent && e.eventType == updateEvent:
+		queued.new = e.new
+	case queued.eventType == updateEvent && e.eventType == deleteEvent:
+		// the handler is handed the last state of the row
+		queued.eventType = deleteEvent
+		queued.old = queued.new
+		queued.new = nil
+	case queued.eventType == deleteEvent && e.eventType == addEvent:
+		queued.eventType = updateEvent
+		queued.new = e.new
+	default:
+		return false
+	}
+	return true
+}
+
+// remove drops a buffered event. Caller must hold the queue lock.
+func (q *handlerQueue) remove(e *event) {
+	for i, queued := range q.events {
+		if queued == e {
+			copy(q.events[i:], q.events[i+1:])
+			q.events[len(q.events)-1] = nil
+			q.events = q.events[:len(q.events)-1]
+			break
+		}
+	}
+	if q.rows[e.table][e.uuid] == e {
+		delete(q.rows[e.table], e.uuid)
+	}
+	q.cond.Broadcast()
+}
+
+// next returns the next event to deliver, or nil and whether the handler has
+// to resync, blocking until there is one or stopCh is closed
+func (q *handlerQueue) next(stopCh <-chan struct{}) (*event, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for {
+		select {
+		case <-stopCh:
+			return nil, false
+		default:
+		}
+		if q.lost {
+			q.lost = false
+			return nil, true
+		}
+		if len(q.events) > 0 {
+			break
+		}
+		q.cond.Wait()
+	}
+	e := q.events[0]
+	q.events[0] = nil
+	q.events = q.events[1:]
+	if q.rows[e.table][e.uuid] == e {
+		delete(q.rows[e.table], e.uuid)
+	}
+	q.cond.Broadcast()
+	return e, false
+}
+
+// setRunning records whether events are being delivered, waking up those
+// waiting on it
+func (q *handlerQueue) setRunning(running bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.running = running
+	q.cond.Broadcast()
+}
+
+// run delivers the buffered events to the handler until stopCh is closed
+func (q *handlerQueue) run(stopCh <-chan struct{}) {
+	for {
+		e, resync := q.next(stopCh)
+		switch {
+		case resync:
+			q.resync.OnResync()
+		case e == nil:
+			return
+		case e.eventType == addEvent:
+			q.handler.OnAdd(e.table, e.new)
+		case e.eventType == updateEvent:
+			q.handler.OnUpdate(e.table, e.old, e.new)
+		case e.eventType == deleteEvent:
+			q.handler.OnDelete(e.table, e.old)
+		}
+	}
+}
+
+// event encapsulates a cache event
+type event struct {
+	eventType string
+	table     string
+	uuid      string
+	old       model.Model
+	new       model.Model
+}
+
+// eventProcessor handles the queueing and processing of cache events
+type eventProcessor struct {
+	// handlersMutex locks the handlers array when we add a handler or
+	// dispatch events
+	handlersMutex sync.Mutex
+	handlers      []*handlerQueue
+	// capacity is the default buffer size of the handlers
+	capacity int
+	// stopCh is set while the eventProcessor runs
+	stopCh <-chan struct{}
+	// held counts the callers holding the events, see hold
+	held   int
+	wg     sync.WaitGroup
+	logger *logr.Logger
+}
+
+func newEventProcessor(capacity int, logger *logr.Logger) *eventProcessor {
+	return &eventProcessor{
+		handlers: []*handlerQueue{},
+		capacity: capacity,
+		logger:   logger,
+	}
+}
+
+// AddEventHandler registers the supplied EventHandler with the eventProcessor.
+// Each handler has its own buffer of events, so a slow handler does not hold
+// up the others; what happens when it falls behind depends on its
+// DeliveryMode
+func (e *eventProcessor) AddEventHandler(handler EventHandler, opts ...EventHandlerOption) {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	q := newHandlerQueue(handler, e.capacity, e.logger, opts...)
+	e.handlers = append(e.handlers, q)
+	if e.stopCh != nil {
+		e.start(q)
+	}
+}
+
+// start delivers the events of a handler until the eventProcessor stops.
+// Caller must hold the handlers lock.
+func (e *eventProcessor) start(q *handlerQueue) {
+	q.setRunning(true)
+	e.wg.Add(1)
+	go func(stopCh <-chan struct{}) {
+		defer e.wg.Done()
+		q.run(stopCh)
+	}(e.stopCh)
+}
+
+// AddEvent buffers an event for each handler
+func (e *eventProcessor) AddEvent(eventType string, table string, uuid string, old model.Model, new model.Model) {
+	e.handlersMutex.Lock()
+	handlers := e.handlers
+	held := e.held > 0
+	e.handlersMutex.Unlock()
+	for _, q := range handlers {
+		q.add(event{
+			eventType: eventType,
+			table:     table,
+			uuid:      uuid,
+			old:       old,
+			new:       new,
+		}, held)
+	}
+}
+
+// hold makes the events buffered without waiting for them to fit in the
+// buffers of the handlers, until release is called
+func (e *eventProcessor) hold() {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	e.held++
+}
+
+// release undoes hold
+func (e *eventProcessor) release() {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	e.held--
+}
+
+// AddEvents buffers a sequence of events for each handler
+func (e *eventProcessor) AddEvents(events []event) {
+	for _, ev := range events {
+		e.AddEvent(ev.eventType, ev.table, ev.uuid, ev.old, ev.new)
+	}
+}
+
+// Run delivers the events to the handlers, each from its own goroutine.
+// It blocks until the stopCh has been closed.
+func (e *eventProcessor) Run(stopCh <-chan struct{}) {
+	e.handlersMutex.Lock()
+	e.stopCh = stopCh
+	for _, q := range e.handlers {
+		e.start(q)
+	}
+	e.handlersMutex.Unlock()
+
+	<-stopCh
+
+	e.handlersMutex.Lock()
+	e.stopCh = nil
+	for _, q := range e.handlers {
+		q.setRunning(false)
+	}
+	e.handlersMutex.Unlock()
+	e.wg.Wait()
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/model"
+)
+
+// recordingHandler records the events delivered to it
+type recordingHandler struct {
+	mutex   sync.Mutex
+	events  []string
+	resyncs int
+	// block, if not nil, holds up the delivery of events until closed
+	block chan struct{}
+}
+
+func (h *recordingHandler) record(eventType string, m model.Model) {
+	if h.block != nil {
+		<-h.block
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.events = append(h.events, eventType+":"+m.(*testModel).Foo)
+}
+
+func (h *recordingHandler) OnAdd(table string, m model.Model) {
+	h.record(addEvent, m)
+}
+
+func (h *recordingHandler) OnUpdate(table string, old, new model.Model) {
+	h.record(updateEvent, new)
+}
+
+func (h *recordingHandler) OnDelete(table string, m model.Model) {
+	h.record(deleteEvent, m)
+}
+
+func (h *recordingHandler) OnResync() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.resyncs++
+}
+
+func (h *recordingHandler) recorded() ([]string, int) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]string{}, h.events...), h.resyncs
+}
+
+func addTestEvents(ep *eventProcessor, n int) {
+	for i := 0; i < n; i++ {
+		ep.AddEvent(addEvent, "Open_vSwitch", "", nil, &testModel{Foo: string(rune('a' + i))})
+	}
+}
+
+func TestEventProcessorDeliveryModes(t *testing.T) {
+	logger := logr.Discard()
+
+	t.Run("drop with resync", func(t *testing.T) {
+		ep := newEventProcessor(2, &logger)
+		handler := &recordingHandler{}
+		ep.AddEventHandler(handler)
+		// the buffered events are superseded by the resync, the events
+		// following it are delivered
+		addTestEvents(ep, 3)
+		ep.AddEvent(addEvent, "Open_vSwitch", "", nil, &testModel{Foo: "z"})
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go ep.Run(stopCh)
+		require.Eventually(t, func() bool {
+			events, resyncs := handler.recorded()
+			return resyncs == 1 && len(events) == 1
+		}, 1*time.Second, 10*time.Millisecond)
+		events, _ := handler.recorded()
+		assert.Equal(t, []string{"add:z"}, events)
+	})
+
+	t.Run("drop without resync", func(t *testing.T) {
+		ep := newEventProcessor(2, &logger)
+		var events []string
+		ep.AddEventHandler(&EventHandlerFuncs{
+			AddFunc: func(table string, m model.Model) {
+				events = append(events, m.(*testModel).Foo)
+			},
+		})
+		addTestEvents(ep, 3)
+		q := ep.handlers[0]
+		assert.Len(t, q.events, 2)
+		assert.False(t, q.lost)
+	})
+
+	t.Run("unbounded", func(t *testing.T) {
+		ep := newEventProcessor(2, &logger)
+		handler := &recordingHandler{}
+		ep.AddEventHandler(handler, WithDeliveryMode(DeliveryUnbounded))
+		addTestEvents(ep, 5)
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go ep.Run(stopCh)
+		require.Eventually(t, func() bool {
+			events, _ := handler.recorded()
+			return len(events) == 5
+		}, 1*time.Second, 10*time.Millisecond)
+		events, resyncs := handler.recorded()
+		assert.Equal(t, []string{"add:a", "add:b", "add:c", "add:d", "add:e"}, events)
+		assert.Zero(t, resyncs)
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		ep := newEventProcessor(1, &logger)
+		handler := &recordingHandler{block: make(chan struct{})}
+		ep.AddEventHandler(handler, WithDeliveryMode(DeliveryBlock))
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go ep.Run(stopCh)
+		q := ep.handlers[0]
+		require.Eventually(t, func() bool {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			return q.running
+		}, 1*time.Second, 10*time.Millisecond)
+
+		// the first event is being delivered, the second one is buffered
+		// and the third one waits for room in the buffer
+		added := make(chan struct{})
+		go func() {
+			addTestEvents(ep, 3)
+			close(added)
+		}()
+		select {
+		case <-added:
+			t.Fatal("events were added while the buffer was full")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(handler.block)
+		<-added
+		require.Eventually(t, func() bool {
+			events, _ := handler.recorded()
+			return len(events) == 3
+		}, 1*time.Second, 10*time.Millisecond)
+		events, resyncs := handler.recorded()
+		assert.Equal(t, []string{"add:a", "add:b", "add:c"}, events)
+		assert.Zero(t, resyncs)
+	})
+
+	t.Run("blocking while not running", func(t *testing.T) {
+		ep := newEventProcessor(1, &logger)
+		ep.AddEventHandler(&recordingHandler{}, WithDeliveryMode(DeliveryBlock))
+		addTestEvents(ep, 3)
+		assert.Len(t, ep.handlers[0].events, 3)
+	})
+}
+
+func TestEventProcessorCoalescing(t *testing.T) {
+	logger := logr.Discard()
+	ep := newEventProcessor(16, &logger)
+	handler := &recordingHandler{}
+	ep.AddEventHandler(handler, WithCoalescing())
+
+	ep.AddEvent(addEvent, "Open_vSwitch", "row1", nil, &testModel{Foo: "a"})
+	ep.AddEvent(updateEvent, "Open_vSwitch", "row1", &testModel{Foo: "a"}, &testModel{Foo: "b"})
+	ep.AddEvent(addEvent, "Open_vSwitch", "row2", nil, &testModel{Foo: "c"})
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "row2", &testModel{Foo: "c"}, nil)
+	ep.AddEvent(updateEvent, "Open_vSwitch", "row3", &testModel{Foo: "d"}, &testModel{Foo: "e"})
+	ep.AddEvent(updateEvent, "Open_vSwitch", "row3", &testModel{Foo: "e"}, &testModel{Foo: "f"})
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "row4", &testModel{Foo: "g"}, nil)
+	ep.AddEvent(addEvent, "Open_vSwitch", "row4", nil, &testModel{Foo: "h"})
+	// the deleted row is the last state of the row
+	ep.AddEvent(updateEvent, "Open_vSwitch", "row5", &testModel{Foo: "i"}, &testModel{Foo: "j"})
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "row5", &testModel{Foo: "j"}, nil)
+	assert.Len(t, ep.handlers[0].events, 4)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go ep.Run(stopCh)
+	require.Eventually(t, func() bool {
+		events, _ := handler.recorded()
+		return len(events) == 4
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// events added once the previous ones were delivered are not merged
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "row1", &testModel{Foo: "b"}, nil)
+	require.Eventually(t, func() bool {
+		events, _ := handler.recorded()
+		return len(events) == 5
+	}, 1*time.Second, 10*time.Millisecond)
+	events, _ := handler.recorded()
+	assert.Equal(t, []string{"add:b", "update:f", "update:h", "delete:j", "delete:b"}, events)
+}
+
+func TestEventProcessorCoalescingCanceledEvents(t *testing.T) {
+	logger := logr.Discard()
+	ep := newEventProcessor(2, &logger)
+	handler := &recordingHandler{}
+	ep.AddEventHandler(handler, WithCoalescing())
+
+	// a row added then deleted leaves no event in the buffer, which has room
+	// for the following ones
+	ep.AddEvent(addEvent, "Open_vSwitch", "row1", nil, &testModel{Foo: "a"})
+	ep.AddEvent(deleteEvent, "Open_vSwitch", "row1", &testModel{Foo: "a"}, nil)
+	ep.AddEvent(addEvent, "Open_vSwitch", "row2", nil, &testModel{Foo: "b"})
+	ep.AddEvent(addEvent, "Open_vSwitch", "row3", nil, &testModel{Foo: "c"})
+	q := ep.handlers[0]
+	assert.Len(t, q.events, 2)
+	assert.False(t, q.lost)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go ep.Run(stopCh)
+	require.Eventually(t, func() bool {
+		events, _ := handler.recorded()
+		return len(events) == 2
+	}, 1*time.Second, 10*time.Millisecond)
+	events, resyncs := handler.recorded()
+	assert.Equal(t, []string{"add:b", "add:c"}, events)
+	assert.Zero(t, resyncs)
+}
@@ -0,0 +1,260 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/mapper"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// orderedEntry is the value of a row in an ordered index
+type orderedEntry struct {
+	value interface{}
+	uuid  string
+}
+
+// orderedIndex holds the rows sorted by the value of a column, then by UUID.
+// Rows with no value for the column, like an empty optional, are left out.
+type orderedIndex []orderedEntry
+
+// orderedValue returns the value to hold in an ordered index, if it can be
+// ordered
+func orderedValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int, float64, string:
+		return v, true
+	case *int:
+		if v != nil {
+			return *v, true
+		}
+	case *float64:
+		if v != nil {
+			return *v, true
+		}
+	case *string:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return nil, false
+}
+
+// compareOrderedValues compares two values of the same type held in an
+// ordered index
+func compareOrderedValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case int:
+		y := b.(int)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	}
+	panic(fmt.Sprintf("%v cannot be ordered", a))
+}
+
+func compareOrderedEntries(a, b orderedEntry) int {
+	if c := compareOrderedValues(a.value, b.value); c != 0 {
+		return c
+	}
+	return strings.Compare(a.uuid, b.uuid)
+}
+
+// insert returns the index with the entry added
+func (o orderedIndex) insert(e orderedEntry) orderedIndex {
+	i := sort.Search(len(o), func(i int) bool { return compareOrderedEntries(o[i], e) >= 0 })
+	if i < len(o) && compareOrderedEntries(o[i], e) == 0 {
+		return o
+	}
+	o = append(o, orderedEntry{})
+	copy(o[i+1:], o[i:])
+	o[i] = e
+	return o
+}
+
+// remove returns the index with the entry removed
+func (o orderedIndex) remove(e orderedEntry) orderedIndex {
+	i := sort.Search(len(o), func(i int) bool { return compareOrderedEntries(o[i], e) >= 0 })
+	if i == len(o) || compareOrderedEntries(o[i], e) != 0 {
+		return o
+	}
+	copy(o[i:], o[i+1:])
+	o[len(o)-1] = orderedEntry{}
+	return o[:len(o)-1]
+}
+
+// matches returns the entries matching a range condition on their value, or
+// false if the condition is not a range or the value is not of the type of
+// the index
+func (o orderedIndex) matches(function ovsdb.ConditionFunction, value interface{}) (orderedIndex, bool) {
+	value, ok := orderedValue(value)
+	if !ok {
+		return nil, false
+	}
+	if len(o) > 0 && reflect.TypeOf(o[0].value) != reflect.TypeOf(value) {
+		return nil, false
+	}
+	// first entries with a value greater than or equal, and greater than the
+	// provided one
+	lower := sort.Search(len(o), func(i int) bool { return compareOrderedValues(o[i].value, value) >= 0 })
+	upper := sort.Search(len(o), func(i int) bool { return compareOrderedValues(o[i].value, value) > 0 })
+	switch function {
+	case ovsdb.ConditionLessThan:
+		return o[:lower], true
+	case ovsdb.ConditionLessThanOrEqual:
+		return o[:upper], true
+	case ovsdb.ConditionGreaterThan:
+		return o[upper:], true
+	case ovsdb.ConditionGreaterThanOrEqual:
+		return o[lower:], true
+	}
+	return nil, false
+}
+
+// updateOrderedIndexes moves a row in the ordered indexes from the values of
+// the old model to the values of the new one. Either of them is nil when the
+// row is created or deleted. Caller must hold the row cache lock.
+func (r *RowCache) updateOrderedIndexes(uuid string, oldInfo, newInfo *mapper.Info) error {
+	for _, spec := range r.indexSpecs {
+		if !spec.ordered {
+			continue
+		}
+		var oldValue, newValue interface{}
+		var hasOld, hasNew bool
+		if oldInfo != nil {
+			value, err := valueFromIndex(oldInfo, spec.columns)
+			if err != nil {
+				return err
+			}
+			oldValue, hasOld = orderedValue(value)
+		}
+		if newInfo != nil {
+			value, err := valueFromIndex(newInfo, spec.columns)
+			if err != nil {
+				return err
+			}
+			newValue, hasNew = orderedValue(value)
+		}
+		if hasOld && hasNew && oldValue == newValue {
+			continue
+		}
+		if hasOld {
+			r.ordered[spec.index] = r.ordered[spec.index].remove(orderedEntry{value: oldValue, uuid: uuid})
+		}
+		if hasNew {
+			r.ordered[spec.index] = r.ordered[spec.index].insert(orderedEntry{value: newValue, uuid: uuid})
+		}
+	}
+	return nil
+}
+
+// uuidsByRangeCondition returns the uuids of the rows matching a range
+// condition through an ordered index on its column, or nil if there is no such
+// index. Caller must hold the row cache lock.
+func (r *RowCache) uuidsByRangeCondition(condition ovsdb.Condition, nativeValue interface{}) uuidset {
+	for _, spec := range r.indexSpecs {
+		if !spec.ordered || spec.columns[0].Column != condition.Column {
+			continue
+		}
+		entries, ok := r.ordered[spec.index].matches(condition.Function, nativeValue)
+		if !ok {
+			return nil
+		}
+		uuids := make(uuidset, len(entries))
+		for _, entry := range entries {
+			uuids.add(entry.uuid)
+		}
+		return uuids
+	}
+	return nil
+}
+
+// Page selects a range of the rows of a table ordered by an ordered client
+// index
+type Page struct {
+	// Descending lists the rows from the greatest value of the index down
+	Descending bool
+	// After, if not nil, lists the rows that follow this one in the order,
+	// typically the last row of the previous page
+	After model.Model
+	// Limit, if positive, is the maximum number of rows listed
+	Limit int
+}
+
+// RowsInOrder returns the rows of the cache ordered by the value of the
+// column of an ordered client index, then by UUID, within the page. Rows with
+// no value for the column, like an empty optional, are not listed.
+func (r *RowCache) RowsInOrder(column string, page Page) ([]model.Model, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var spec *indexSpec
+	for i := range r.indexSpecs {
+		if r.indexSpecs[i].ordered && r.indexSpecs[i].columns[0].Column == column {
+			spec = &r.indexSpecs[i]
+			break
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("%s is not an ordered index of table %s", column, r.name)
+	}
+	entries := r.ordered[spec.index]
+
+	start, end, step := 0, len(entries), 1
+	if page.Descending {
+		start, end, step = len(entries)-1, -1, -1
+	}
+	if page.After != nil {
+		info, err := r.dbModel.NewModelInfo(page.After)
+		if err != nil {
+			return nil, err
+		}
+		uuid, err := info.FieldByColumn("_uuid")
+		if err != nil {
+			return nil, err
+		}
+		value, err := valueFromIndex(info, spec.columns)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := orderedValue(value)
+		if !ok {
+			return nil, fmt.Errorf("the model to list after has no value for column %s", column)
+		}
+		after := orderedEntry{value: value, uuid: uuid.(string)}
+		if len(entries) > 0 && reflect.TypeOf(entries[0].value) != reflect.TypeOf(value) {
+			return nil, fmt.Errorf("the model to list after has a value of the wrong type for column %s", column)
+		}
+		if page.Descending {
+			start = sort.Search(len(entries), func(i int) bool { return compareOrderedEntries(entries[i], after) >= 0 }) - 1
+		} else {
+			start = sort.Search(len(entries), func(i int) bool { return compareOrderedEntries(entries[i], after) > 0 })
+		}
+	}
+
+	rows := []model.Model{}
+	for i := start; i != end; i += step {
+		if page.Limit > 0 && len(rows) >= page.Limit {
+			break
+		}
+		rows = append(rows, r.rowByUUID(entries[i].uuid))
+	}
+	return rows, nil
+}
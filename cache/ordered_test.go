@@ -0,0 +1,222 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+type orderedTestModel struct {
+	UUID     string  `ovsdb:"_uuid"`
+	Name     string  `ovsdb:"name"`
+	Priority int     `ovsdb:"priority"`
+	Tag      *int    `ovsdb:"tag"`
+	Weight   float64 `ovsdb:"weight"`
+}
+
+func setupOrderedCache(t *testing.T, data Data) *TableCache {
+	var schema ovsdb.DatabaseSchema
+	db, err := model.NewClientDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &orderedTestModel{}})
+	require.NoError(t, err)
+	db.SetIndexes(map[string][]model.ClientIndex{
+		"Open_vSwitch": {
+			{Type: model.OrderedIndexType, Columns: []model.ColumnKey{{Column: "name"}}},
+			{Type: model.OrderedIndexType, Columns: []model.ColumnKey{{Column: "priority"}}},
+			{Type: model.OrderedIndexType, Columns: []model.ColumnKey{{Column: "tag"}}},
+		},
+	})
+	err = json.Unmarshal([]byte(`{
+		"name": "Open_vSwitch",
+		"tables": {
+		  "Open_vSwitch": {
+			"indexes": [["name"]],
+			"columns": {
+			  "name": {"type": "string"},
+			  "priority": {"type": "integer"},
+			  "tag": {"type": {"key": "integer", "min": 0, "max": 1}},
+			  "weight": {"type": "real"}
+			}
+		  }
+		}
+	}`), &schema)
+	require.NoError(t, err)
+	dbModel, errs := model.NewDatabaseModel(schema, db)
+	require.Empty(t, errs)
+	tc, err := NewTableCache(dbModel, data, nil)
+	require.NoError(t, err)
+	return tc
+}
+
+func TestRowCacheRowsByRangeCondition(t *testing.T) {
+	tag := 10
+	rows := map[string]model.Model{}
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		rows[name] = &orderedTestModel{UUID: name, Name: name, Priority: i * 100, Weight: float64(i)}
+	}
+	rows["e"].(*orderedTestModel).Tag = &tag
+	tc := setupOrderedCache(t, Data{"Open_vSwitch": rows})
+	rc := tc.Table("Open_vSwitch")
+
+	tests := []struct {
+		name      string
+		condition ovsdb.Condition
+		// uuids of the candidates found through the ordered index, nil if
+		// it cannot be used
+		candidates uuidset
+		expected   []string
+	}{
+		{
+			name:       "less than",
+			condition:  ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionLessThan, Value: 200},
+			candidates: newUUIDSet("a", "b"),
+			expected:   []string{"a", "b"},
+		},
+		{
+			name:       "less than or equal",
+			condition:  ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionLessThanOrEqual, Value: 200},
+			candidates: newUUIDSet("a", "b", "c"),
+			expected:   []string{"a", "b", "c"},
+		},
+		{
+			name:       "greater than",
+			condition:  ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionGreaterThan, Value: 250},
+			candidates: newUUIDSet("d", "e"),
+			expected:   []string{"d", "e"},
+		},
+		{
+			name:       "greater than or equal",
+			condition:  ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionGreaterThanOrEqual, Value: 400},
+			candidates: newUUIDSet("e"),
+			expected:   []string{"e"},
+		},
+		{
+			name:       "no match",
+			condition:  ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionGreaterThan, Value: 400},
+			candidates: newUUIDSet(),
+			expected:   []string{},
+		},
+		{
+			name:       "optional",
+			condition:  ovsdb.Condition{Column: "tag", Function: ovsdb.ConditionGreaterThan, Value: ovsdb.OvsSet{GoSet: []interface{}{5}}},
+			candidates: newUUIDSet("e"),
+		},
+		{
+			name:      "not indexed",
+			condition: ovsdb.Condition{Column: "weight", Function: ovsdb.ConditionLessThan, Value: 2.0},
+			expected:  []string{"a", "b"},
+		},
+		{
+			name:      "not a range",
+			condition: ovsdb.Condition{Column: "priority", Function: ovsdb.ConditionNotEqual, Value: 0},
+			expected:  []string{"b", "c", "d", "e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nativeValue, err := ovsdb.OvsToNative(rc.dbModel.Schema.Table("Open_vSwitch").Column(tt.condition.Column), tt.condition.Value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.candidates, rc.uuidsByRangeCondition(tt.condition, nativeValue))
+			if tt.expected == nil {
+				return
+			}
+			found, err := rc.RowsByCondition([]ovsdb.Condition{tt.condition})
+			require.NoError(t, err)
+			expected := map[string]model.Model{}
+			for _, uuid := range tt.expected {
+				expected[uuid] = rows[uuid]
+			}
+			assert.Equal(t, expected, found)
+		})
+	}
+
+	// range conditions are combined with the other conditions
+	found, err := rc.RowsByCondition([]ovsdb.Condition{
+		{Column: "priority", Function: ovsdb.ConditionGreaterThan, Value: 100},
+		{Column: "priority", Function: ovsdb.ConditionLessThan, Value: 400},
+		{Column: "name", Function: ovsdb.ConditionNotEqual, Value: "c"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.Model{"d": rows["d"]}, found)
+}
+
+func TestRowCacheRowsInOrder(t *testing.T) {
+	tag := 1
+	tc := setupOrderedCache(t, Data{"Open_vSwitch": {
+		"u1": &orderedTestModel{UUID: "u1", Name: "c", Priority: 20},
+		"u2": &orderedTestModel{UUID: "u2", Name: "a", Priority: 10, Tag: &tag},
+		"u3": &orderedTestModel{UUID: "u3", Name: "b", Priority: 20},
+		"u4": &orderedTestModel{UUID: "u4", Name: "d", Priority: 5},
+	}})
+	rc := tc.Table("Open_vSwitch")
+
+	uuids := func(rows []model.Model) []string {
+		result := []string{}
+		for _, row := range rows {
+			result = append(result, row.(*orderedTestModel).UUID)
+		}
+		return result
+	}
+
+	rows, err := rc.RowsInOrder("name", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3", "u1", "u4"}, uuids(rows))
+
+	// rows with the same value are ordered by UUID
+	rows, err = rc.RowsInOrder("priority", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u2", "u1", "u3"}, uuids(rows))
+
+	rows, err = rc.RowsInOrder("priority", Page{Descending: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u1", "u2", "u4"}, uuids(rows))
+
+	// pages follow the last row of the previous one
+	rows, err = rc.RowsInOrder("priority", Page{Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u2", "u1"}, uuids(rows))
+	rows, err = rc.RowsInOrder("priority", Page{Limit: 3, After: rows[2]})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, uuids(rows))
+	rows, err = rc.RowsInOrder("priority", Page{Limit: 3, After: rows[0]})
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	rows, err = rc.RowsInOrder("priority", Page{Descending: true, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u1"}, uuids(rows))
+	rows, err = rc.RowsInOrder("priority", Page{Descending: true, Limit: 2, After: rows[1]})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u4"}, uuids(rows))
+
+	// empty optionals are not listed
+	rows, err = rc.RowsInOrder("tag", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, uuids(rows))
+
+	_, err = rc.RowsInOrder("weight", Page{})
+	assert.Error(t, err)
+
+	// the order follows the updates of the rows, but not in snapshots
+	snapshot := tc.Snapshot()
+	_, err = rc.Update("u4", &orderedTestModel{UUID: "u4", Name: "d", Priority: 30}, true)
+	require.NoError(t, err)
+	err = rc.Delete("u2")
+	require.NoError(t, err)
+	err = rc.Create("u5", &orderedTestModel{UUID: "u5", Name: "e", Priority: 20, Tag: &tag}, true)
+	require.NoError(t, err)
+	rows, err = rc.RowsInOrder("priority", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u3", "u5", "u4"}, uuids(rows))
+	rows, err = rc.RowsInOrder("tag", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u5"}, uuids(rows))
+
+	rows, err = snapshot.Table("Open_vSwitch").RowsInOrder("priority", Page{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u2", "u1", "u3"}, uuids(rows))
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/mapper"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// referenceSpec specifies a column of a table holding references to the rows
+// of another table
+type referenceSpec struct {
+	column string
+	// mapValue flags if the references are the values, rather than the
+	// keys, of a map column
+	mapValue bool
+	toTable  string
+}
+
+// referenceIndex maps the UUIDs of the rows referenced from a column to the
+// UUIDs of the rows referencing them
+type referenceIndex map[string]uuidset
+
+// newReferenceSpecs returns the columns of a table that reference other tables
+func newReferenceSpecs(tableSchema *ovsdb.TableSchema) []referenceSpec {
+	var specs []referenceSpec
+	for column, columnSchema := range tableSchema.Columns {
+		if columnSchema.TypeObj == nil {
+			continue
+		}
+		for _, side := range []struct {
+			baseType *ovsdb.BaseType
+			mapValue bool
+		}{
+			{columnSchema.TypeObj.Key, false},
+			{columnSchema.TypeObj.Value, true},
+		} {
+			if side.baseType == nil || side.baseType.Type != ovsdb.TypeUUID {
+				continue
+			}
+			toTable, err := side.baseType.RefTable()
+			if err != nil || toTable == "" {
+				continue
+			}
+			specs = append(specs, referenceSpec{column: column, mapValue: side.mapValue, toTable: toTable})
+		}
+	}
+	return specs
+}
+
+// referencedUUIDs returns the UUIDs held by the native value of a reference
+// column
+func referencedUUIDs(value interface{}, mapValue bool) []string {
+	var uuids []string
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() != "" {
+			uuids = append(uuids, v.String())
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			uuids = referencedUUIDs(v.Elem().Interface(), mapValue)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			uuids = append(uuids, referencedUUIDs(v.Index(i).Interface(), mapValue)...)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if mapValue {
+				uuids = append(uuids, referencedUUIDs(iter.Value().Interface(), false)...)
+			} else {
+				uuids = append(uuids, referencedUUIDs(iter.Key().Interface(), false)...)
+			}
+		}
+	}
+	return uuids
+}
+
+// rowReferences returns the UUIDs referenced by a row for each reference
+// column of the model
+func (r *RowCache) rowReferences(info *mapper.Info) map[referenceSpec][]string {
+	references := make(map[referenceSpec][]string, len(r.referenceSpecs))
+	for _, spec := range r.referenceSpecs {
+		value, err := info.FieldByColumn(spec.column)
+		if err != nil {
+			// the column is not part of the model
+			continue
+		}
+		references[spec] = referencedUUIDs(value, spec.mapValue)
+	}
+	return references
+}
+
+// addReferences indexes the references of a row. Caller must hold the row
+// cache lock.
+func (r *RowCache) addReferences(uuid string, references map[referenceSpec][]string) {
+	for spec, to := range references {
+		index := r.references[spec]
+		for _, toUUID := range to {
+			if index[toUUID] == nil {
+				index[toUUID] = uuidset{}
+			}
+			index[toUUID].add(uuid)
+		}
+	}
+}
+
+// removeReferences removes the references of a row from the index. Caller
+// must hold the row cache lock.
+func (r *RowCache) removeReferences(uuid string, references map[referenceSpec][]string) {
+	for spec, to := range references {
+		index := r.references[spec]
+		for _, toUUID := range to {
+			index[toUUID].remove(uuid)
+			if index[toUUID].empty() {
+				delete(index, toUUID)
+			}
+		}
+	}
+}
+
+// RowsReferencing returns the rows of the cache that reference the row of
+// the provided table and UUID from any of the provided columns, or from any
+// column if none is provided
+func (r *RowCache) RowsReferencing(table, uuid string, columns ...string) map[string]model.Model {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	results := map[string]model.Model{}
+	for _, spec := range r.referenceSpecs {
+		if spec.toTable != table {
+			continue
+		}
+		if len(columns) > 0 && !containsColumn(columns, spec.column) {
+			continue
+		}
+		for from := range r.references[spec][uuid] {
+			if _, ok := results[from]; !ok {
+				results[from] = r.rowByUUID(from)
+			}
+		}
+	}
+	return results
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// RowsReferencing returns the rows of all tables that reference the row of
+// the provided table and UUID, by table
+func (t *TableCache) RowsReferencing(table, uuid string) map[string]map[string]model.Model {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	results := map[string]map[string]model.Model{}
+	for name, rowCache := range t.cache {
+		rows := rowCache.RowsReferencing(table, uuid)
+		if len(rows) > 0 {
+			results[name] = rows
+		}
+	}
+	return results
+}
+
+// RowsReferencedBy returns the rows referenced by the row of the provided
+// table and UUID from any of the provided columns, or from any column if none
+// is provided, by table. Referenced rows missing from the cache are ignored.
+func (t *TableCache) RowsReferencedBy(table, uuid string, columns ...string) map[string]map[string]model.Model {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	results := map[string]map[string]model.Model{}
+	rowCache, ok := t.cache[table]
+	if !ok {
+		return results
+	}
+	references := rowCache.referencesOf(uuid)
+	for spec, to := range references {
+		if len(columns) > 0 && !containsColumn(columns, spec.column) {
+			continue
+		}
+		toCache, ok := t.cache[spec.toTable]
+		if !ok {
+			continue
+		}
+		for _, toUUID := range to {
+			row := toCache.Row(toUUID)
+			if row == nil {
+				continue
+			}
+			if results[spec.toTable] == nil {
+				results[spec.toTable] = map[string]model.Model{}
+			}
+			results[spec.toTable][toUUID] = row
+		}
+	}
+	return results
+}
+
+// referencesOf returns the UUIDs referenced by the row of the provided UUID
+// for each reference column of the model
+func (r *RowCache) referencesOf(uuid string) map[referenceSpec][]string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	row, ok := r.cache[uuid]
+	if !ok {
+		return nil
+	}
+	info, err := r.dbModel.NewModelInfo(row)
+	if err != nil {
+		return nil
+	}
+	return r.rowReferences(info)
+}
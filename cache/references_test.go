@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/test"
+)
+
+func TestTableCacheRowsReferencing(t *testing.T) {
+	dbModel, err := test.GetModel()
+	require.NoError(t, err)
+	port1 := &test.PortType{UUID: "port1", Name: "port1"}
+	port2 := &test.PortType{UUID: "port2", Name: "port2"}
+	mirror1 := &test.MirrorType{UUID: "mirror1", Name: "mirror1", SelectSrcPort: []string{"port1", "port2"}}
+	mirror2 := &test.MirrorType{UUID: "mirror2", Name: "mirror2", SelectSrcPort: []string{"port1"}}
+	bridge := &test.BridgeType{UUID: "bridge", Name: "bridge", Mirrors: []string{"mirror1"}}
+	manager := &test.ManagerType{UUID: "manager", Target: "ptcp:6640"}
+	ovs := &test.OvsType{UUID: "ovs", ManagerOptions: []string{"manager"}}
+	tc, err := NewTableCache(dbModel, Data{
+		"Port":         {"port1": port1, "port2": port2},
+		"Mirror":       {"mirror1": mirror1, "mirror2": mirror2},
+		"Bridge":       {"bridge": bridge},
+		"Manager":      {"manager": manager},
+		"Open_vSwitch": {"ovs": ovs},
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]model.Model{"mirror1": mirror1, "mirror2": mirror2}, tc.Table("Mirror").RowsReferencing("Port", "port1"))
+	assert.Equal(t, map[string]model.Model{"mirror1": mirror1}, tc.Table("Mirror").RowsReferencing("Port", "port2"))
+	assert.Equal(t, map[string]map[string]model.Model{
+		"Bridge": {"bridge": bridge},
+	}, tc.RowsReferencing("Mirror", "mirror1"))
+	assert.Empty(t, tc.RowsReferencing("Mirror", "mirror2"))
+	assert.Equal(t, map[string]map[string]model.Model{
+		"Open_vSwitch": {"ovs": ovs},
+	}, tc.RowsReferencing("Manager", "manager"))
+
+	assert.Equal(t, map[string]map[string]model.Model{
+		"Port": {"port1": port1, "port2": port2},
+	}, tc.RowsReferencedBy("Mirror", "mirror1"))
+	assert.Equal(t, map[string]map[string]model.Model{
+		"Mirror": {"mirror1": mirror1},
+	}, tc.RowsReferencedBy("Bridge", "bridge", "mirrors"))
+	assert.Empty(t, tc.RowsReferencedBy("Bridge", "bridge", "ports"))
+	assert.Empty(t, tc.RowsReferencedBy("Bridge", "missing"))
+
+	// the references can be restricted to some columns
+	assert.Len(t, tc.Table("Mirror").RowsReferencing("Port", "port1", "select_src_port"), 2)
+	assert.Empty(t, tc.Table("Mirror").RowsReferencing("Port", "port1", "name"))
+
+	// the references are updated with the rows
+	snapshot := tc.Snapshot()
+	updated := &test.MirrorType{UUID: "mirror1", Name: "mirror1", SelectSrcPort: []string{"port1"}}
+	_, err = tc.Table("Mirror").Update("mirror1", updated, true)
+	require.NoError(t, err)
+	assert.Empty(t, tc.Table("Mirror").RowsReferencing("Port", "port2"))
+	assert.Equal(t, map[string]model.Model{"mirror1": updated, "mirror2": mirror2}, tc.Table("Mirror").RowsReferencing("Port", "port1"))
+
+	err = tc.Table("Mirror").Delete("mirror2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.Model{"mirror1": updated}, tc.Table("Mirror").RowsReferencing("Port", "port1"))
+
+	row := ovsdb.Row{"name": "mirror3", "select_src_port": ovsdb.OvsSet{GoSet: []interface{}{ovsdb.UUID{GoUUID: "port2"}}}}
+	err = tc.Populate2(ovsdb.TableUpdates2{"Mirror": {"mirror3": &ovsdb.RowUpdate2{Insert: &row}}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]model.Model{
+		"Mirror": {"mirror3": &test.MirrorType{UUID: "mirror3", Name: "mirror3", SelectSrcPort: []string{"port2"}}},
+	}, tc.RowsReferencing("Port", "port2"))
+
+	// snapshots keep their own references
+	assert.Equal(t, map[string]model.Model{"mirror1": mirror1}, snapshot.Table("Mirror").RowsReferencing("Port", "port2"))
+	assert.Len(t, snapshot.Table("Mirror").RowsReferencing("Port", "port1"), 2)
+}
+
+func TestReferencedUUIDs(t *testing.T) {
+	uuid := "uuid"
+	assert.Equal(t, []string{"uuid"}, referencedUUIDs("uuid", false))
+	assert.Empty(t, referencedUUIDs("", false))
+	assert.Equal(t, []string{"uuid"}, referencedUUIDs(&uuid, false))
+	assert.Empty(t, referencedUUIDs((*string)(nil), false))
+	assert.Equal(t, []string{"uuid1", "uuid2"}, referencedUUIDs([]string{"uuid1", "uuid2"}, false))
+	assert.Equal(t, []string{"uuid"}, referencedUUIDs(map[string]int{"uuid": 1}, false))
+	assert.Empty(t, referencedUUIDs(map[string]int{"uuid": 1}, true))
+	assert.Equal(t, []string{"uuid"}, referencedUUIDs(map[int]string{1: "uuid"}, true))
+}
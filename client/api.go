@@ -43,6 +43,25 @@ type API interface {
 	// conditions.
 	WhereAll(model.Model, ...model.Condition) ConditionalAPI
 
+	// WhereReferencing creates a ConditionalAPI where operations apply to the
+	// rows of the table of the first model that reference the row matching
+	// the second one, either by UUID or indexes. Optional fields (pointers to
+	// fields in the first model) restrict the references to those columns.
+	// The rows are found through the reverse references indexed by the
+	// cache, without scanning the table
+	WhereReferencing(model.Model, model.Model, ...interface{}) ConditionalAPI
+
+	// WhereReferencedBy creates a ConditionalAPI where operations apply to the
+	// rows referenced from a column of the row matching the model, either by
+	// UUID or indexes. The field is a pointer to the field of the reference
+	// column in the model, and the rows are those of the table it references
+	WhereReferencedBy(model.Model, interface{}) ConditionalAPI
+
+	// OrderBy creates an OrderedAPI that lists the rows of the table of the
+	// model ordered by a field, a pointer to a field of the model whose
+	// column has an ordered client index
+	OrderBy(m model.Model, field interface{}) OrderedAPI
+
 	// Get retrieves a model from the cache
 	// The way the object will be fetch depends on the data contained in the
 	// provided model and the indexes defined in the associated schema
@@ -50,11 +69,23 @@ type API interface {
 	// preferred way is Where({condition}).List()
 	Get(context.Context, model.Model) error
 
+	// Snapshot returns an API that reads from a point-in-time copy of the
+	// cache, consistent across all tables as of the last update fully
+	// applied to it. The copy is not updated any further, which makes it
+	// suitable to read related rows of different tables
+	Snapshot(context.Context) API
+
 	// Create returns the operation needed to add the model(s) to the Database
 	// Only fields with non-default values will be added to the transaction. If
 	// the field associated with column "_uuid" has some content other than a
 	// UUID, it will be treated as named-uuid
 	Create(...model.Model) ([]ovsdb.Operation, error)
+
+	// Assert returns the operation needed to assert that the client owns the
+	// lock identified by id, as requested with Lock or Steal. A transaction
+	// including it fails with a "not owner" error if the client does not
+	// own the lock
+	Assert(id string) ovsdb.Operation
 }
 
 // ConditionalAPI is an interface used to perform operations that require / use Conditions
@@ -198,6 +229,35 @@ func (a api) WhereCache(predicate interface{}) ConditionalAPI {
 	return newConditionalAPI(a.cache, a.conditionFromFunc(predicate), a.logger)
 }
 
+// WhereReferencing returns a conditionalAPI based on the references to the
+// row matching the target model
+func (a api) WhereReferencing(m model.Model, target model.Model, fields ...interface{}) ConditionalAPI {
+	return newConditionalAPI(a.cache, a.conditionFromReferencing(m, target, fields...), a.logger)
+}
+
+// WhereReferencedBy returns a conditionalAPI based on the references from a
+// column of the row matching the model
+func (a api) WhereReferencedBy(m model.Model, field interface{}) ConditionalAPI {
+	return newConditionalAPI(a.cache, a.conditionFromReferencedBy(m, field), a.logger)
+}
+
+// OrderBy returns an OrderedAPI listing the rows ordered by a field
+func (a api) OrderBy(m model.Model, field interface{}) OrderedAPI {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return orderedAPI{err: err}
+	}
+	return newOrderedAPI(a.cache, table, m, field)
+}
+
+// Assert returns the operation needed to assert the ownership of a lock
+func (a api) Assert(id string) ovsdb.Operation {
+	return ovsdb.Operation{
+		Op:   ovsdb.OperationAssert,
+		Lock: &id,
+	}
+}
+
 // Conditional interface implementation
 // FromFunc returns a Condition from a function
 func (a api) conditionFromFunc(predicate interface{}) Conditional {
@@ -248,6 +308,34 @@ func (a api) conditionFromExplicitConditions(matchAll bool, m model.Model, cond
 	return conditional
 }
 
+// conditionFromReferencing returns a Conditional for the rows of the table of
+// a model that reference the row matching the target model
+func (a api) conditionFromReferencing(m model.Model, target model.Model, fields ...interface{}) Conditional {
+	tableName, err := a.getTableFromModel(m)
+	if tableName == "" {
+		return newErrorConditional(err)
+	}
+	conditional, err := newReferencingConditional(tableName, a.cache, m, target, fields...)
+	if err != nil {
+		return newErrorConditional(err)
+	}
+	return conditional
+}
+
+// conditionFromReferencedBy returns a Conditional for the rows referenced from
+// a column of the row matching the model
+func (a api) conditionFromReferencedBy(m model.Model, field interface{}) Conditional {
+	tableName, err := a.getTableFromModel(m)
+	if tableName == "" {
+		return newErrorConditional(err)
+	}
+	conditional, err := newReferencedConditional(tableName, a.cache, m, field)
+	if err != nil {
+		return newErrorConditional(err)
+	}
+	return conditional
+}
+
 // Get is a generic Get function capable of returning (through a provided pointer)
 // a instance of any row in the cache.
 // 'result' must be a pointer to an Model that exists in the ClientDBModel
@@ -277,6 +365,11 @@ func (a api) Get(ctx context.Context, m model.Model) error {
 	return nil
 }
 
+// Snapshot returns an API that reads from a snapshot of the cache
+func (a api) Snapshot(ctx context.Context) API {
+	return newAPI(a.cache.Snapshot(), a.logger)
+}
+
 // Create is a generic function capable of creating any row in the DB
 // A valid Model (pointer to object) must be provided.
 func (a api) Create(models ...model.Model) ([]ovsdb.Operation, error) {
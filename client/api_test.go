@@ -741,6 +741,126 @@ func TestAPIGet(t *testing.T) {
 	}
 }
 
+func TestAPISnapshot(t *testing.T) {
+	lsp := &testLogicalSwitchPort{
+		UUID: aUUID2,
+		Name: "lsp0",
+	}
+	ls := &testLogicalSwitch{
+		UUID:  aUUID0,
+		Name:  "ls0",
+		Ports: []string{aUUID2},
+	}
+	testData := cache.Data{
+		"Logical_Switch":      map[string]model.Model{aUUID0: ls},
+		"Logical_Switch_Port": map[string]model.Model{aUUID2: lsp},
+	}
+	tcache := apiTestCache(t, testData)
+	snapshot := newAPI(tcache, &discardLogger).Snapshot(context.Background())
+
+	// the port is deleted from the cache after the snapshot is taken
+	err := tcache.Table("Logical_Switch").Delete(aUUID0)
+	require.NoError(t, err)
+	err = tcache.Table("Logical_Switch_Port").Delete(aUUID2)
+	require.NoError(t, err)
+
+	// the snapshot still holds the switch and its port
+	var switches []testLogicalSwitch
+	err = snapshot.List(context.Background(), &switches)
+	require.NoError(t, err)
+	require.Len(t, switches, 1)
+	assert.Equal(t, *ls, switches[0])
+	port := &testLogicalSwitchPort{UUID: switches[0].Ports[0]}
+	err = snapshot.Get(context.Background(), port)
+	require.NoError(t, err)
+	assert.Equal(t, lsp, port)
+
+	err = newAPI(tcache, &discardLogger).Get(context.Background(), &testLogicalSwitchPort{UUID: aUUID2})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAPIReferences(t *testing.T) {
+	lsp0 := &testLogicalSwitchPort{
+		UUID: aUUID2,
+		Name: "lsp0",
+	}
+	lsp1 := &testLogicalSwitchPort{
+		UUID: aUUID3,
+		Name: "lsp1",
+	}
+	ls0 := &testLogicalSwitch{
+		UUID:  aUUID0,
+		Name:  "ls0",
+		Ports: []string{aUUID2, aUUID3},
+	}
+	ls1 := &testLogicalSwitch{
+		UUID:  aUUID1,
+		Name:  "ls1",
+		Ports: []string{aUUID3},
+	}
+	tcache := apiTestCache(t, cache.Data{
+		"Logical_Switch":      map[string]model.Model{aUUID0: ls0, aUUID1: ls1},
+		"Logical_Switch_Port": map[string]model.Model{aUUID2: lsp0, aUUID3: lsp1},
+	})
+	api := newAPI(tcache, &discardLogger)
+
+	t.Run("WhereReferencing", func(t *testing.T) {
+		var switches []*testLogicalSwitch
+		err := api.WhereReferencing(&testLogicalSwitch{}, &testLogicalSwitchPort{Name: "lsp1"}).List(context.Background(), &switches)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*testLogicalSwitch{ls0, ls1}, switches)
+
+		ls := &testLogicalSwitch{}
+		switches = nil
+		err = api.WhereReferencing(ls, &testLogicalSwitchPort{UUID: aUUID2}, &ls.Ports).List(context.Background(), &switches)
+		require.NoError(t, err)
+		assert.Equal(t, []*testLogicalSwitch{ls0}, switches)
+
+		// the operations apply to the referencing rows
+		ops, err := api.WhereReferencing(ls, lsp0).Mutate(ls, model.Mutation{
+			Field:   &ls.Ports,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   []string{aUUID2},
+		})
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, []ovsdb.Condition{{
+			Column:   "_uuid",
+			Function: ovsdb.ConditionEqual,
+			Value:    ovsdb.UUID{GoUUID: aUUID0},
+		}}, ops[0].Where)
+
+		// the columns must reference the table of the target
+		_, err = api.WhereReferencing(ls, lsp0, &ls.Acls).Delete()
+		assert.Error(t, err)
+		_, err = api.WhereReferencing(ls, lsp0, &ls.Name).Delete()
+		assert.Error(t, err)
+	})
+
+	t.Run("WhereReferencedBy", func(t *testing.T) {
+		ls := &testLogicalSwitch{UUID: aUUID0}
+		var ports []*testLogicalSwitchPort
+		err := api.WhereReferencedBy(ls, &ls.Ports).List(context.Background(), &ports)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*testLogicalSwitchPort{lsp0, lsp1}, ports)
+
+		ls = &testLogicalSwitch{UUID: aUUID1}
+		ops, err := api.WhereReferencedBy(ls, &ls.Ports).Delete()
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, "Logical_Switch_Port", ops[0].Table)
+		assert.Equal(t, []ovsdb.Condition{{
+			Column:   "_uuid",
+			Function: ovsdb.ConditionEqual,
+			Value:    ovsdb.UUID{GoUUID: aUUID3},
+		}}, ops[0].Where)
+
+		// a column that is not a reference is an error
+		_, err = api.WhereReferencedBy(ls, &ls.Name).Delete()
+		assert.Error(t, err)
+	})
+}
+
 func TestAPICreate(t *testing.T) {
 	lsCacheList := []model.Model{}
 	lspCacheList := []model.Model{
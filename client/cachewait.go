@@ -0,0 +1,427 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/updates"
+)
+
+type cacheWaitKey struct{}
+
+// WaitForCache returns a copy of ctx that makes Transact, once the transaction
+// is committed, wait until the rows monitored by the client that it changes
+// have been updated in the cache with its changes. The changed rows are
+// determined from the inserted rows and from the rows in the cache the
+// operations apply to; rows inserted in tables that are only monitored with
+// conditions are not waited for. An update of a row only counts if the row
+// then holds the values written by the transaction, or the elements it
+// inserted or deleted, in the monitored columns, or if it was deleted;
+// arithmetic mutations are reflected by any update of the row. The rows might
+// have been changed again by other clients since. If ctx is done before the cache is updated,
+// Transact returns the results of the transaction along with the error of
+// the context.
+func WaitForCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheWaitKey{}, true)
+}
+
+func waitForCacheFromContext(ctx context.Context) bool {
+	wait, _ := ctx.Value(cacheWaitKey{}).(bool)
+	return wait
+}
+
+// cacheExpectation is a row of the cache expected to be updated once a
+// transaction is committed
+type cacheExpectation struct {
+	table string
+	uuid  string
+	// insert is the index of the operation inserting the row, whose result
+	// holds its UUID, or -1 if the row is not inserted by the transaction
+	insert int
+	// deleted tells whether the transaction deletes the row
+	deleted bool
+	// operations are the update and mutate operations of the transaction
+	// on the row, and columns the monitored columns of the row, or nil if
+	// all of them are
+	operations []*ovsdb.Operation
+	columns    map[string]bool
+}
+
+// reflected returns whether the row of the cache holds the changes of the
+// transaction. Assumes the cache lock of the database is held.
+func (e *cacheExpectation) reflected(tableCache *cache.TableCache) bool {
+	rowCache := tableCache.Table(e.table)
+	if rowCache == nil {
+		return false
+	}
+	// a row deleted by another client won't be updated anymore
+	row := rowCache.Row(e.uuid)
+	if e.deleted || row == nil {
+		return row == nil
+	}
+	dbModel := tableCache.DatabaseModel()
+	rowInfo, err := dbModel.NewModelInfo(row)
+	if err != nil {
+		return false
+	}
+	// the row holds the changes of an operation if applying it again does
+	// not change the columns it writes, unless a later operation writes them
+	checked := map[string]bool{}
+	for i := len(e.operations) - 1; i >= 0; i-- {
+		op := e.operations[i]
+		columns := writtenColumns(op)
+		modelUpdates := updates.ModelUpdates{}
+		if err := modelUpdates.AddOperation(dbModel, e.table, e.uuid, row, op); err != nil {
+			return false
+		}
+		new := modelUpdates.GetModel(e.table, e.uuid)
+		if new == nil {
+			for _, column := range columns {
+				checked[column] = true
+			}
+			continue
+		}
+		newInfo, err := dbModel.NewModelInfo(new)
+		if err != nil {
+			return false
+		}
+		for _, column := range columns {
+			if checked[column] || e.columns != nil && !e.columns[column] {
+				continue
+			}
+			checked[column] = true
+			value, err := rowInfo.FieldByColumn(column)
+			if err != nil {
+				continue
+			}
+			newValue, _ := newInfo.FieldByColumn(column)
+			if !ovsdb.EqualNativeValues(rowInfo.Metadata.TableSchema.Column(column), value, newValue) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// writtenColumns returns the columns that an update or mutate operation
+// writes, leaving out the ones of arithmetic mutations that change the row
+// every time they are applied
+func writtenColumns(op *ovsdb.Operation) []string {
+	var columns []string
+	for column := range op.Row {
+		columns = append(columns, column)
+	}
+	for _, mutation := range op.Mutations {
+		if mutation.Mutator == ovsdb.MutateOperationInsert || mutation.Mutator == ovsdb.MutateOperationDelete {
+			columns = append(columns, mutation.Column)
+		}
+	}
+	return columns
+}
+
+// cacheWaiter records which of the rows expected to be updated by a
+// transaction were updated in the cache of a database with its changes
+type cacheWaiter struct {
+	mutex        sync.Mutex
+	expectations []cacheExpectation
+	// reflected holds the expectations met by the updates recorded so far
+	reflected map[int]bool
+	// updated holds the uuids of the updated rows by table, for the rows
+	// inserted by the transaction whose uuids are only known once it is
+	// committed
+	updated map[string]map[string]bool
+	// repopulated is set when the cache was populated again upon
+	// reconnection, after which it reflects every committed transaction
+	repopulated bool
+}
+
+// record records the update of a row of the cache. Assumes the cache lock of
+// the database is held.
+func (w *cacheWaiter) record(tableCache *cache.TableCache, table, uuid string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.updated[table] == nil {
+		w.updated[table] = map[string]bool{}
+	}
+	w.updated[table][uuid] = true
+	for i := range w.expectations {
+		e := &w.expectations[i]
+		if w.reflected[i] || e.insert >= 0 || e.table != table || e.uuid != uuid {
+			continue
+		}
+		w.reflected[i] = e.reflected(tableCache)
+	}
+}
+
+// met returns whether the cache was updated with the changes expected by the
+// expectation at index i, whose row has the provided uuid
+func (w *cacheWaiter) met(i int, uuid string) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	e := w.expectations[i]
+	if e.insert >= 0 {
+		return w.repopulated || w.updated[e.table][uuid]
+	}
+	return w.repopulated || w.reflected[i]
+}
+
+// addCacheWaiter starts recording the updates of the rows of the cache of
+// the database expected to be updated by a transaction
+func (db *database) addCacheWaiter(expectations []cacheExpectation) *cacheWaiter {
+	w := &cacheWaiter{
+		expectations: expectations,
+		reflected:    map[int]bool{},
+		updated:      map[string]map[string]bool{},
+	}
+	db.cacheWaitersMutex.Lock()
+	defer db.cacheWaitersMutex.Unlock()
+	if db.cacheWaiters == nil {
+		db.cacheWaiters = map[*cacheWaiter]bool{}
+	}
+	db.cacheWaiters[w] = true
+	return w
+}
+
+// removeCacheWaiter stops recording the rows updated for a waiter
+func (db *database) removeCacheWaiter(w *cacheWaiter) {
+	db.cacheWaitersMutex.Lock()
+	defer db.cacheWaitersMutex.Unlock()
+	delete(db.cacheWaiters, w)
+}
+
+// forEachCacheWaiter calls do for the waiters of the database
+func (db *database) forEachCacheWaiter(do func(*cacheWaiter)) {
+	db.cacheWaitersMutex.Lock()
+	defer db.cacheWaitersMutex.Unlock()
+	for w := range db.cacheWaiters {
+		do(w)
+	}
+}
+
+// recordTableUpdates records the rows of table updates applied to the cache.
+// It has to be called once the updates are applied, before any other update
+// is, with the cache lock held.
+func (db *database) recordTableUpdates(updates ovsdb.TableUpdates) {
+	db.forEachCacheWaiter(func(w *cacheWaiter) {
+		for table, rows := range updates {
+			for uuid := range rows {
+				w.record(db.cache, table, uuid)
+			}
+		}
+	})
+}
+
+// recordTableUpdates2 records the rows of table updates applied to the cache.
+// It has to be called once the updates are applied, before any other update
+// is, with the cache lock held.
+func (db *database) recordTableUpdates2(updates ovsdb.TableUpdates2) {
+	db.forEachCacheWaiter(func(w *cacheWaiter) {
+		for table, rows := range updates {
+			for uuid := range rows {
+				w.record(db.cache, table, uuid)
+			}
+		}
+	})
+}
+
+// recordRepopulated records that the cache was populated again upon
+// reconnection
+func (db *database) recordRepopulated() {
+	db.forEachCacheWaiter(func(w *cacheWaiter) {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		w.repopulated = true
+	})
+}
+
+// monitoredTable describes how a table is monitored
+type monitoredTable struct {
+	// columns are the monitored columns, or nil if all of them are
+	columns map[string]bool
+	// conditional tells whether the table is only monitored with conditions
+	conditional bool
+}
+
+// monitoredTables returns how the tables of the database are monitored
+func monitoredTables(db *database) map[string]*monitoredTable {
+	db.monitorsMutex.Lock()
+	defer db.monitorsMutex.Unlock()
+	tables := map[string]*monitoredTable{}
+	for _, monitor := range db.monitors {
+		for _, tableMonitor := range monitor.Tables {
+			table, ok := tables[tableMonitor.Table]
+			if !ok {
+				table = &monitoredTable{columns: map[string]bool{}, conditional: true}
+				tables[tableMonitor.Table] = table
+			}
+			if len(tableMonitor.Conditions) == 0 {
+				table.conditional = false
+			}
+			if len(tableMonitor.Fields) == 0 {
+				table.columns = nil
+			}
+			if table.columns != nil {
+				for _, column := range tableMonitor.Fields {
+					table.columns[column] = true
+				}
+			}
+		}
+	}
+	return tables
+}
+
+// expectCacheUpdates returns the rows of the cache of the database expected
+// to be updated once the operations are committed. It has to be called before
+// the operations are sent, so that they are applied to the rows of the cache
+// they will be applied to by the server.
+func expectCacheUpdates(db *database, operations []ovsdb.Operation) []cacheExpectation {
+	tables := monitoredTables(db)
+	db.cacheMutex.RLock()
+	defer db.cacheMutex.RUnlock()
+	if db.cache == nil {
+		return nil
+	}
+	dbModel := db.cache.DatabaseModel()
+
+	var expectations []cacheExpectation
+	modelUpdates := updates.ModelUpdates{}
+	// current holds the state of the rows the operations were applied to,
+	// nil if deleted, and rowOperations the update and mutate operations
+	// applied to them
+	current := map[string]map[string]model.Model{}
+	rowOperations := map[string]map[string][]*ovsdb.Operation{}
+	for i := range operations {
+		op := &operations[i]
+		if _, ok := tables[op.Table]; !ok {
+			continue
+		}
+		switch op.Op {
+		case ovsdb.OperationInsert:
+			if !tables[op.Table].conditional {
+				expectations = append(expectations, cacheExpectation{table: op.Table, insert: i})
+			}
+			continue
+		case ovsdb.OperationUpdate, ovsdb.OperationMutate, ovsdb.OperationDelete:
+		default:
+			continue
+		}
+		rowCache := db.cache.Table(op.Table)
+		if rowCache == nil {
+			continue
+		}
+		// rows inserted by the transaction are referred to by named UUIDs
+		// and can't be matched against the cache
+		rows, err := rowCache.RowsByCondition(op.Where)
+		if err != nil {
+			continue
+		}
+		if current[op.Table] == nil {
+			current[op.Table] = map[string]model.Model{}
+			rowOperations[op.Table] = map[string][]*ovsdb.Operation{}
+		}
+		for uuid, row := range rows {
+			if m, ok := current[op.Table][uuid]; ok {
+				if m == nil {
+					continue
+				}
+				row = m
+			}
+			if err := modelUpdates.AddOperation(dbModel, op.Table, uuid, row, op); err != nil {
+				continue
+			}
+			current[op.Table][uuid] = modelUpdates.GetModel(op.Table, uuid)
+			if op.Op != ovsdb.OperationDelete {
+				rowOperations[op.Table][uuid] = append(rowOperations[op.Table][uuid], op)
+			}
+		}
+	}
+
+	for _, table := range modelUpdates.GetUpdatedTables() {
+		monitored := tables[table]
+		_ = modelUpdates.ForEachModelUpdate(table, func(uuid string, old, new model.Model) error {
+			expectation := cacheExpectation{table: table, uuid: uuid, insert: -1}
+			if new == nil {
+				expectation.deleted = true
+				expectations = append(expectations, expectation)
+				return nil
+			}
+			expectation.operations = rowOperations[table][uuid]
+			expectation.columns = monitored.columns
+			oldInfo, err := dbModel.NewModelInfo(old)
+			if err != nil {
+				return nil
+			}
+			newInfo, err := dbModel.NewModelInfo(new)
+			if err != nil {
+				return nil
+			}
+			for column := range newInfo.Metadata.Fields {
+				if column == "_uuid" || monitored.columns != nil && !monitored.columns[column] {
+					continue
+				}
+				oldValue, _ := oldInfo.FieldByColumn(column)
+				newValue, _ := newInfo.FieldByColumn(column)
+				// the row is only updated if a monitored column changes
+				if !ovsdb.EqualNativeValues(newInfo.Metadata.TableSchema.Column(column), oldValue, newValue) {
+					expectations = append(expectations, expectation)
+					break
+				}
+			}
+			return nil
+		})
+	}
+	return expectations
+}
+
+// waitForCacheUpdates waits until the rows of the cache of the database
+// expected to be updated by the committed transaction were updated with its
+// changes since the waiter was added, given the results of the transaction
+func waitForCacheUpdates(ctx context.Context, db *database, w *cacheWaiter, results []ovsdb.OperationResult) error {
+	pending := make(map[int]string, len(w.expectations))
+	for i, e := range w.expectations {
+		if e.insert >= 0 {
+			pending[i] = results[e.insert].UUID.GoUUID
+		} else {
+			pending[i] = e.uuid
+		}
+	}
+	return waitForCache(ctx, db, func(*cache.TableCache) bool {
+		for i, uuid := range pending {
+			if !w.met(i, uuid) {
+				return false
+			}
+			delete(pending, i)
+		}
+		return true
+	})
+}
+
+// waitForCache polls the cache of the database until done returns true or
+// ctx is done
+func waitForCache(ctx context.Context, db *database, done func(*cache.TableCache) bool) error {
+	check := func() bool {
+		db.cacheMutex.RLock()
+		defer db.cacheMutex.RUnlock()
+		return db.cache != nil && done(db.cache)
+	}
+	if check() {
+		return nil
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: while awaiting cache update", ctx.Err())
+		case <-ticker.C:
+			if check() {
+				return nil
+			}
+		}
+	}
+}
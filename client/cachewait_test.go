@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForCacheUpdates(t *testing.T) {
+	ovs, err := newOVSDBClient(defDB)
+	require.NoError(t, err)
+	var s ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(schema), &s)
+	require.NoError(t, err)
+	dbModel, errs := model.NewDatabaseModel(s, defDB)
+	require.Empty(t, errs)
+	db := ovs.primaryDB()
+	db.cache, err = cache.NewTableCache(dbModel, cache.Data{
+		"Bridge": {
+			aUUID0: &Bridge{UUID: aUUID0, Name: "foo", DatapathType: "system"},
+			aUUID1: &Bridge{UUID: aUUID1, Name: "bar"},
+		},
+		"Open_vSwitch": {
+			aUUID2: &OpenvSwitch{UUID: aUUID2, Bridges: []string{aUUID0, aUUID1}},
+		},
+	}, nil)
+	require.NoError(t, err)
+	db.monitors["all"] = &Monitor{Tables: []TableMonitor{
+		{Table: "Bridge"},
+		{Table: "Open_vSwitch", Fields: []string{"bridges"}},
+	}}
+
+	externalIDs, err := ovsdb.NewOvsMap(map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+	ops := []ovsdb.Operation{
+		{
+			Op:       ovsdb.OperationInsert,
+			Table:    "Bridge",
+			Row:      ovsdb.Row{"name": "baz"},
+			UUIDName: "baz",
+		},
+		{
+			Op:    ovsdb.OperationUpdate,
+			Table: "Bridge",
+			Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})},
+			Row:   ovsdb.Row{"external_ids": externalIDs},
+		},
+		{
+			// does not change the row
+			Op:    ovsdb.OperationUpdate,
+			Table: "Bridge",
+			Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "foo")},
+			Row:   ovsdb.Row{"datapath_type": "system"},
+		},
+		{
+			// does not change monitored columns
+			Op:        ovsdb.OperationMutate,
+			Table:     "Open_vSwitch",
+			Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("cur_cfg", ovsdb.MutateOperationAdd, 1)},
+		},
+		{
+			Op:    ovsdb.OperationDelete,
+			Table: "Bridge",
+			Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "bar")},
+		},
+	}
+	expectations := expectCacheUpdates(db, ops)
+	expected := []cacheExpectation{
+		{table: "Bridge", insert: 0},
+		{table: "Bridge", uuid: aUUID0, insert: -1, operations: []*ovsdb.Operation{&ops[1], &ops[2]}},
+		{table: "Bridge", uuid: aUUID1, insert: -1, deleted: true},
+	}
+	assert.ElementsMatch(t, expected, expectations)
+	waiter := db.addCacheWaiter(expectations)
+	defer db.removeCacheWaiter(waiter)
+
+	update := func(updates ovsdb.TableUpdates2) {
+		t.Helper()
+		err := db.cache.Update2(nil, updates)
+		require.NoError(t, err)
+		db.recordTableUpdates2(updates)
+	}
+	results := []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID3}}, {Count: 1}, {Count: 1}, {Count: 1}, {Count: 1}}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = waitForCacheUpdates(ctx, db, waiter, results)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the rows are updated by another client before the transaction is
+	// committed, which does not count for the rows it updates
+	update(ovsdb.TableUpdates2{
+		"Bridge": {
+			aUUID3: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "baz"}},
+			aUUID0: &ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "netdev"}},
+			aUUID1: &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}},
+		},
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = waitForCacheUpdates(ctx, db, waiter, results)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	update(ovsdb.TableUpdates2{
+		"Bridge": {
+			aUUID0: &ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "system", "external_ids": externalIDs}},
+		},
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = waitForCacheUpdates(ctx, db, waiter, results)
+	assert.NoError(t, err)
+
+	// a cache populated again reflects every committed transaction
+	other := db.addCacheWaiter(expectations)
+	defer db.removeCacheWaiter(other)
+	db.recordRepopulated()
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = waitForCacheUpdates(ctx, db, other, results)
+	assert.NoError(t, err)
+}
@@ -65,8 +65,16 @@ type Client interface {
 	Monitor(context.Context, *Monitor) (MonitorCookie, error)
 	MonitorAll(context.Context) (MonitorCookie, error)
 	MonitorCancel(ctx context.Context, cookie MonitorCookie) error
+	MonitorCondChange(ctx context.Context, cookie MonitorCookie, opts ...MonitorOption) error
 	NewMonitor(...MonitorOption) *Monitor
 	CurrentEndpoint() string
+	Lock(ctx context.Context, id string) (bool, error)
+	Steal(ctx context.Context, id string) error
+	Unlock(ctx context.Context, id string) error
+	HasLock(id string) bool
+	Database(name string) (DatabaseClient, error)
+	NewTransaction() Transaction
+	OptimisticTransact(context.Context, backoff.BackOff, func(OptimisticTransaction) error) ([]ModelError, error)
 	API
 }
 
@@ -97,6 +105,12 @@ type ovsdbClient struct {
 	primaryDBName string
 	databases     map[string]*database
 
+	// locks requested by the client, how they were requested and whether
+	// they are currently owned, so we can request them again if we
+	// disconnect
+	locks      map[string]*lockRequest
+	locksMutex sync.Mutex
+
 	errorCh       chan error
 	stopCh        chan struct{}
 	disconnect    chan struct{}
@@ -131,12 +145,23 @@ type database struct {
 	// tracks any outstanding updates while waiting for a monitor response
 	deferUpdates    bool
 	deferredUpdates []*bufferedUpdate
+
+	// cacheWaiters record the rows updated in the cache for the
+	// transactions waiting for it, see WaitForCache
+	cacheWaiters      map[*cacheWaiter]bool
+	cacheWaitersMutex sync.Mutex
+	// cacheUpdateMutex serializes the updates of the cache received from
+	// the server, so that each is recorded for the waiters, along with the
+	// last transaction ID of the monitor for update3, before the next one
+	// is applied. It is locked before cacheMutex.
+	cacheUpdateMutex sync.Mutex
 }
 
 // NewOVSDBClient creates a new OVSDB Client with the provided
 // database model. The client can be configured using one or more Option(s),
 // like WithTLSConfig. If no WithEndpoint option is supplied, the default of
-// unix:/var/run/openvswitch/ovsdb.sock is used
+// unix:/var/run/openvswitch/ovsdb.sock is used. Other databases can be accessed
+// over the same connection by providing their models with WithDatabaseModel.
 func NewOVSDBClient(clientDBModel model.ClientDBModel, opts ...Option) (Client, error) {
 	return newOVSDBClient(clientDBModel, opts...)
 }
@@ -153,6 +178,7 @@ func newOVSDBClient(clientDBModel model.ClientDBModel, opts ...Option) (*ovsdbCl
 				deferredUpdates: make([]*bufferedUpdate, 0),
 			},
 		},
+		locks:           make(map[string]*lockRequest),
 		errorCh:         make(chan error),
 		handlerShutdown: &sync.WaitGroup{},
 		disconnect:      make(chan struct{}),
@@ -196,9 +222,36 @@ func newOVSDBClient(clientDBModel model.ClientDBModel, opts ...Option) (*ovsdbCl
 		}
 	}
 
+	if err := ovs.addDatabases(ovs.options.databaseModels); err != nil {
+		return nil, err
+	}
+
 	return ovs, nil
 }
 
+// addDatabases adds the databases of the provided models, as provided with
+// WithDatabaseModel, to the ones the client interacts with
+func (o *ovsdbClient) addDatabases(clientDBModels []model.ClientDBModel) error {
+	// the databases are checked first so that none is added on error
+	names := make(map[string]bool, len(clientDBModels))
+	for _, clientDBModel := range clientDBModels {
+		name := clientDBModel.Name()
+		if _, ok := o.databases[name]; ok || names[name] {
+			return fmt.Errorf("database %s is provided more than once", name)
+		}
+		names[name] = true
+	}
+	for _, clientDBModel := range clientDBModels {
+		o.databases[clientDBModel.Name()] = &database{
+			model:           model.NewPartialDatabaseModel(clientDBModel),
+			monitors:        make(map[string]*Monitor),
+			deferUpdates:    true,
+			deferredUpdates: make([]*bufferedUpdate, 0),
+		}
+	}
+	return nil
+}
+
 // Connect opens a connection to an OVSDB Server using the
 // endpoint provided when the Client was created.
 // The connection can be configured using one or more Option(s), like WithTLSConfig
@@ -303,6 +356,12 @@ func (o *ovsdbClient) connect(ctx context.Context, reconnect bool) error {
 				}
 			}
 		}
+
+		o.logger.V(3).Info("reconnected - requesting locks")
+		if err := o.restartLocks(ctx); err != nil {
+			o.resetRPCClient()
+			return err
+		}
 	}
 
 	go o.handleDisconnectNotification()
@@ -427,7 +486,7 @@ func (o *ovsdbClient) createRPC2Client(conn net.Conn) {
 	if o.options.inactivityTimeout > 0 {
 		o.trafficSeen = make(chan struct{})
 	}
-	o.rpcClient = rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+	o.rpcClient = rpc2.NewClientWithCodec(jsonCodec{jsonrpc.NewJSONCodec(conn)})
 	o.rpcClient.SetBlocking(true)
 	o.rpcClient.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
 		return o.echo(args, reply)
@@ -441,6 +500,12 @@ func (o *ovsdbClient) createRPC2Client(conn net.Conn) {
 	o.rpcClient.Handle("update3", func(_ *rpc2.Client, args []json.RawMessage, reply *[]interface{}) error {
 		return o.update3(args, reply)
 	})
+	o.rpcClient.Handle("locked", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return o.locked(args)
+	})
+	o.rpcClient.Handle("stolen", func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+		return o.stolen(args)
+	})
 	go o.rpcClient.Run()
 }
 
@@ -514,20 +579,14 @@ func (o *ovsdbClient) primaryDB() *database {
 // Schema returns the DatabaseSchema that is being used by the client
 // it will be nil until a connection has been established
 func (o *ovsdbClient) Schema() ovsdb.DatabaseSchema {
-	db := o.primaryDB()
-	db.modelMutex.RLock()
-	defer db.modelMutex.RUnlock()
-	return db.model.Schema
+	return o.databaseClient(o.primaryDBName).Schema()
 }
 
 // Cache returns the TableCache that is populated from
 // ovsdb update notifications. It will be nil until a connection
 // has been established, and empty unless you call Monitor
 func (o *ovsdbClient) Cache() *cache.TableCache {
-	db := o.primaryDB()
-	db.cacheMutex.RLock()
-	defer db.cacheMutex.RUnlock()
-	return db.cache
+	return o.databaseClient(o.primaryDBName).Cache()
 }
 
 // UpdateEndpoints sets client endpoints
@@ -572,7 +631,17 @@ func (o *ovsdbClient) SetOption(opt Option) error {
 	if o.rpcClient != nil {
 		return fmt.Errorf("cannot set option when client is connected")
 	}
-	return opt(o.options)
+	// the option is applied to a copy of the options, that replaces them
+	// once the databases it provides, if any, are added
+	options := *o.options
+	if err := opt(&options); err != nil {
+		return err
+	}
+	if err := o.addDatabases(options.databaseModels[len(o.options.databaseModels):]); err != nil {
+		return err
+	}
+	*o.options = options
+	return nil
 }
 
 // Connected returns whether or not the client is currently connected to the server
@@ -631,6 +700,8 @@ func (o *ovsdbClient) update(params []json.RawMessage, reply *[]interface{}) err
 		o.metrics.numTableUpdates.WithLabelValues(cookie.DatabaseName, tableName).Inc()
 	}
 
+	db.cacheUpdateMutex.Lock()
+	defer db.cacheUpdateMutex.Unlock()
 	db.cacheMutex.Lock()
 	if db.deferUpdates {
 		db.deferredUpdates = append(db.deferredUpdates, &bufferedUpdate{&updates, nil, ""})
@@ -639,9 +710,16 @@ func (o *ovsdbClient) update(params []json.RawMessage, reply *[]interface{}) err
 	}
 	db.cacheMutex.Unlock()
 
-	// Update the local DB cache with the tableUpdates
+	// Update the local DB cache with the tableUpdates. The event handlers
+	// might read the cache, so updating it must not wait for them while
+	// holding the cache mutex
 	db.cacheMutex.RLock()
+	db.cache.HoldEvents()
 	err = db.cache.Update(cookie.ID, updates)
+	if err == nil {
+		db.recordTableUpdates(updates)
+	}
+	db.cache.ReleaseEvents()
 	db.cacheMutex.RUnlock()
 
 	if err != nil {
@@ -672,6 +750,8 @@ func (o *ovsdbClient) update2(params []json.RawMessage, reply *[]interface{}) er
 		return fmt.Errorf("update: invalid database name: %s unknown", cookie.DatabaseName)
 	}
 
+	db.cacheUpdateMutex.Lock()
+	defer db.cacheUpdateMutex.Unlock()
 	db.cacheMutex.Lock()
 	if db.deferUpdates {
 		db.deferredUpdates = append(db.deferredUpdates, &bufferedUpdate{nil, &updates, ""})
@@ -680,9 +760,16 @@ func (o *ovsdbClient) update2(params []json.RawMessage, reply *[]interface{}) er
 	}
 	db.cacheMutex.Unlock()
 
-	// Update the local DB cache with the tableUpdates
+	// Update the local DB cache with the tableUpdates. The event handlers
+	// might read the cache, so updating it must not wait for them while
+	// holding the cache mutex
 	db.cacheMutex.RLock()
+	db.cache.HoldEvents()
 	err = db.cache.Update2(cookie, updates)
+	if err == nil {
+		db.recordTableUpdates2(updates)
+	}
+	db.cache.ReleaseEvents()
 	db.cacheMutex.RUnlock()
 
 	if err != nil {
@@ -719,6 +806,8 @@ func (o *ovsdbClient) update3(params []json.RawMessage, reply *[]interface{}) er
 		return fmt.Errorf("update: invalid database name: %s unknown", cookie.DatabaseName)
 	}
 
+	db.cacheUpdateMutex.Lock()
+	defer db.cacheUpdateMutex.Unlock()
 	db.cacheMutex.Lock()
 	if db.deferUpdates {
 		db.deferredUpdates = append(db.deferredUpdates, &bufferedUpdate{nil, &updates, lastTransactionID})
@@ -727,17 +816,22 @@ func (o *ovsdbClient) update3(params []json.RawMessage, reply *[]interface{}) er
 	}
 	db.cacheMutex.Unlock()
 
-	// Update the local DB cache with the tableUpdates
+	// Update the local DB cache with the tableUpdates. The event handlers
+	// might read the cache, so updating it must not wait for them while
+	// holding the cache mutex
 	db.cacheMutex.RLock()
+	db.cache.HoldEvents()
 	err = db.cache.Update2(cookie, updates)
-	db.cacheMutex.RUnlock()
-
 	if err == nil {
+		db.recordTableUpdates2(updates)
 		db.monitorsMutex.Lock()
-		mon := db.monitors[cookie.ID]
-		mon.LastTransactionID = lastTransactionID
+		if mon, ok := db.monitors[cookie.ID]; ok {
+			mon.LastTransactionID = lastTransactionID
+		}
 		db.monitorsMutex.Unlock()
 	}
+	db.cache.ReleaseEvents()
+	db.cacheMutex.RUnlock()
 
 	return err
 }
@@ -781,9 +875,17 @@ func (o *ovsdbClient) logFromContext(ctx context.Context) *logr.Logger {
 	return o.logger
 }
 
-// Transact performs the provided Operations on the database
+// Transact performs the provided Operations on the database. If the context
+// was returned by WaitForCache, it waits until the rows changed by the
+// transaction are updated in the cache
 // RFC 7047 : transact
 func (o *ovsdbClient) Transact(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	return o.transactWhenConnected(ctx, o.primaryDBName, operation...)
+}
+
+// transactWhenConnected performs the provided Operations on the named
+// database, waiting for the client to reconnect if needed
+func (o *ovsdbClient) transactWhenConnected(ctx context.Context, dbName string, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
 	logger := o.logFromContext(ctx)
 	o.rpcMutex.RLock()
 	if o.rpcClient == nil || !o.connected {
@@ -810,8 +912,25 @@ func (o *ovsdbClient) Transact(ctx context.Context, operation ...ovsdb.Operation
 			return nil, ErrNotConnected
 		}
 	}
-	defer o.rpcMutex.RUnlock()
-	return o.transact(ctx, o.primaryDBName, false, operation...)
+	if !waitForCacheFromContext(ctx) {
+		defer o.rpcMutex.RUnlock()
+		return o.transact(ctx, dbName, false, operation...)
+	}
+
+	db := o.databases[dbName]
+	waiter := db.addCacheWaiter(expectCacheUpdates(db, operation))
+	defer db.removeCacheWaiter(waiter)
+	reply, err := o.transact(ctx, dbName, false, operation...)
+	// the cache might need a reconnection to be updated
+	o.rpcMutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ovsdb.CheckOperationResults(reply, operation); err != nil {
+		// the transaction was not committed
+		return reply, nil
+	}
+	return reply, waitForCacheUpdates(ctx, db, waiter, reply)
 }
 
 func (o *ovsdbClient) transact(ctx context.Context, dbName string, skipChWrite bool, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
@@ -836,7 +955,23 @@ func (o *ovsdbClient) transact(ctx context.Context, dbName string, skipChWrite b
 	if dbgLogger.Enabled() {
 		dbgLogger.Info("transacting operations", "operations", fmt.Sprintf("%+v", operation))
 	}
-	err := o.rpcClient.CallWithContext(ctx, "transact", args, &reply)
+	request := &requestWithSeq{args: args}
+	call := o.rpcClient.Go("transact", request, &reply, make(chan *rpc2.Call, 1))
+	var err error
+	select {
+	case <-call.Done:
+		err = call.Error
+	case <-ctx.Done():
+		// RFC 7047 : cancel
+		// the server aborts the transaction unless it is already complete,
+		// the outcome of which is then unknown
+		if request.seq != 0 {
+			if err := o.rpcClient.Notify("cancel", ovsdb.NewCancelArgs(request.seq)); err != nil {
+				logger.V(3).Error(err, "failed to cancel transaction")
+			}
+		}
+		err = ctx.Err()
+	}
 	if err != nil {
 		if err == rpc2.ErrShutdown {
 			return nil, ErrNotConnected
@@ -852,11 +987,7 @@ func (o *ovsdbClient) transact(ctx context.Context, dbName string, skipChWrite b
 
 // MonitorAll is a convenience method to monitor every table/column
 func (o *ovsdbClient) MonitorAll(ctx context.Context) (MonitorCookie, error) {
-	m := newMonitor()
-	for name := range o.primaryDB().model.Types() {
-		m.Tables = append(m.Tables, TableMonitor{Table: name})
-	}
-	return o.Monitor(ctx, m)
+	return o.databaseClient(o.primaryDBName).MonitorAll(ctx)
 }
 
 // MonitorCancel will request cancel a previously issued monitor request
@@ -879,23 +1010,103 @@ func (o *ovsdbClient) MonitorCancel(ctx context.Context, cookie MonitorCookie) e
 	if reply.Error != "" {
 		return fmt.Errorf("error while executing transaction: %s", reply.Error)
 	}
-	o.primaryDB().monitorsMutex.Lock()
-	defer o.primaryDB().monitorsMutex.Unlock()
-	delete(o.primaryDB().monitors, cookie.ID)
+	db := o.databases[cookie.DatabaseName]
+	if db == nil {
+		return fmt.Errorf("invalid database name: %s unknown", cookie.DatabaseName)
+	}
+	db.monitorsMutex.Lock()
+	defer db.monitorsMutex.Unlock()
+	delete(db.monitors, cookie.ID)
 	o.metrics.numMonitors.Dec()
 	return nil
 }
 
+// MonitorCondChange changes the conditions of tables monitored by a
+// previously issued monitor request. Tables are provided through MonitorOption
+// like WithConditionalTable, and their conditions replace the ones the tables
+// are currently monitored with; monitored columns are not changed. Rows that
+// start or stop matching the conditions are added to or removed from the
+// cache asynchronously, as the server sends the resulting updates, which may
+// be after this function returns.
+// ovsdb-server.7 : monitor_cond_change
+func (o *ovsdbClient) MonitorCondChange(ctx context.Context, cookie MonitorCookie, opts ...MonitorOption) error {
+	db := o.databases[cookie.DatabaseName]
+	if db == nil {
+		return fmt.Errorf("invalid database name: %s unknown", cookie.DatabaseName)
+	}
+	change := newDatabaseMonitor(db, opts...)
+	if len(change.Errors) != 0 {
+		var errString []string
+		for _, err := range change.Errors {
+			errString = append(errString, err.Error())
+		}
+		return fmt.Errorf(strings.Join(errString, ". "))
+	}
+
+	o.rpcMutex.RLock()
+	defer o.rpcMutex.RUnlock()
+	if o.rpcClient == nil {
+		return ErrNotConnected
+	}
+
+	// monitorsMutex can't be held while waiting for the reply as the
+	// server might send updates that need it to be processed
+	db.monitorsMutex.Lock()
+	monitor, ok := db.monitors[cookie.ID]
+	if !ok {
+		db.monitorsMutex.Unlock()
+		return fmt.Errorf("monitor %s not found", cookie.ID)
+	}
+	if monitor.Method == ovsdb.MonitorRPC {
+		db.monitorsMutex.Unlock()
+		return fmt.Errorf("%w: monitor %s does not support conditions", ErrUnsupportedRPC, cookie.ID)
+	}
+	tables := make([]TableMonitor, len(monitor.Tables))
+	copy(tables, monitor.Tables)
+	db.monitorsMutex.Unlock()
+
+	requests := make(map[string][]ovsdb.MonitorCondChangeRequest, len(change.Tables))
+	for _, t := range change.Tables {
+		found := false
+		for i := range tables {
+			if tables[i].Table != t.Table {
+				continue
+			}
+			tables[i].Conditions = t.Conditions
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("table %s is not monitored by monitor %s", t.Table, cookie.ID)
+		}
+		conditions := t.Conditions
+		if conditions == nil {
+			conditions = []ovsdb.Condition{}
+		}
+		requests[t.Table] = []ovsdb.MonitorCondChangeRequest{{Where: conditions}}
+	}
+
+	var reply struct{}
+	args := ovsdb.NewMonitorCondChangeArgs(cookie, cookie, requests)
+	err := o.rpcClient.CallWithContext(ctx, "monitor_cond_change", args, &reply)
+	if err != nil {
+		if err == rpc2.ErrShutdown {
+			return ErrNotConnected
+		}
+		return err
+	}
+
+	db.monitorsMutex.Lock()
+	defer db.monitorsMutex.Unlock()
+	monitor.Tables = tables
+	return nil
+}
+
 // Monitor will provide updates for a given table/column
 // and populate the cache with them. Subsequent updates will be processed
 // by the Update Notifications
 // RFC 7047 : monitor
 func (o *ovsdbClient) Monitor(ctx context.Context, monitor *Monitor) (MonitorCookie, error) {
-	cookie := newMonitorCookie(o.primaryDBName)
-	db := o.databases[o.primaryDBName]
-	db.monitorsMutex.Lock()
-	defer db.monitorsMutex.Unlock()
-	return cookie, o.monitor(ctx, cookie, false, monitor)
+	return o.databaseClient(o.primaryDBName).Monitor(ctx, monitor)
 }
 
 // If fields is provided, the request will be constrained to the provided columns
@@ -990,9 +1201,11 @@ func (o *ovsdbClient) monitor(ctx context.Context, cookie MonitorCookie, reconne
 	case ovsdb.ConditionalMonitorSinceRPC:
 		var reply ovsdb.MonitorCondSinceReply
 		err = o.rpcClient.CallWithContext(ctx, monitor.Method, args, &reply)
-		if err == nil && reply.Found {
+		if err == nil {
+			// the last transaction id is valid whether or not the one we
+			// provided was found
 			monitor.LastTransactionID = reply.LastTransactionID
-			lastTransactionFound = true
+			lastTransactionFound = reply.Found
 		}
 		tableUpdates = reply.Updates
 	default:
@@ -1025,6 +1238,10 @@ func (o *ovsdbClient) monitor(ctx context.Context, cookie MonitorCookie, reconne
 
 	db.cacheMutex.Lock()
 	defer db.cacheMutex.Unlock()
+	// the event handlers might read the cache, so populating it must not wait
+	// for them while holding the cache mutex
+	db.cache.HoldEvents()
+	defer db.cache.ReleaseEvents()
 
 	// On reconnect, purge the cache _unless_ the only monitor is a
 	// MonitorCondSince one, whose LastTransactionID was known to the
@@ -1038,14 +1255,23 @@ func (o *ovsdbClient) monitor(ctx context.Context, cookie MonitorCookie, reconne
 	if monitor.Method == ovsdb.MonitorRPC {
 		u := tableUpdates.(ovsdb.TableUpdates)
 		err = db.cache.Populate(u)
+		if err == nil && !reconnecting {
+			db.recordTableUpdates(u)
+		}
 	} else {
 		u := tableUpdates.(ovsdb.TableUpdates2)
 		err = db.cache.Populate2(u)
+		if err == nil && !reconnecting {
+			db.recordTableUpdates2(u)
+		}
 	}
 
 	if err != nil {
 		return err
 	}
+	if reconnecting {
+		db.recordRepopulated()
+	}
 
 	// populate any deferred updates
 	db.deferUpdates = false
@@ -1054,12 +1280,14 @@ func (o *ovsdbClient) monitor(ctx context.Context, cookie MonitorCookie, reconne
 			if err = db.cache.Populate(*update.updates); err != nil {
 				return err
 			}
+			db.recordTableUpdates(*update.updates)
 		}
 
 		if update.updates2 != nil {
 			if err = db.cache.Populate2(*update.updates2); err != nil {
 				return err
 			}
+			db.recordTableUpdates2(*update.updates2)
 		}
 		if len(update.lastTxnID) > 0 {
 			db.monitors[cookie.ID].LastTransactionID = update.lastTxnID
@@ -1274,6 +1502,8 @@ func (o *ovsdbClient) handleDisconnectNotification() {
 		close(o.trafficSeen)
 	}
 	o.metrics.numDisconnects.Inc()
+	// locks are released by the server when the connection is lost
+	o.releaseLocks()
 	// wait for client related handlers to shutdown
 	o.handlerShutdown.Wait()
 	o.rpcMutex.Lock()
@@ -1336,6 +1566,10 @@ func (o *ovsdbClient) handleDisconnectNotification() {
 	}
 	o.metrics.numMonitors.Set(0)
 
+	o.locksMutex.Lock()
+	o.locks = make(map[string]*lockRequest)
+	o.locksMutex.Unlock()
+
 	o.shutdownMutex.Lock()
 	defer o.shutdownMutex.Unlock()
 	o.shutdown = false
@@ -1438,43 +1672,74 @@ func hasMonitors(db *database) bool {
 // We add this wrapper to allow users to access the API directly on the
 // client object
 
+// NewTransaction returns a new Transaction on the database
+func (o *ovsdbClient) NewTransaction() Transaction {
+	return o.databaseClient(o.primaryDBName).NewTransaction()
+}
+
+// OptimisticTransact runs fn to build a transaction that is only performed if
+// the rows it reads have not changed in the meantime, and runs it again
+// otherwise. See DatabaseClient's OptimisticTransact.
+func (o *ovsdbClient) OptimisticTransact(ctx context.Context, backOff backoff.BackOff, fn func(OptimisticTransaction) error) ([]ModelError, error) {
+	return o.databaseClient(o.primaryDBName).OptimisticTransact(ctx, backOff, fn)
+}
+
 // Get implements the API interface's Get function
 func (o *ovsdbClient) Get(ctx context.Context, model model.Model) error {
-	primaryDB := o.primaryDB()
-	waitForCacheConsistent(ctx, primaryDB, o.logger, o.primaryDBName)
-	defer primaryDB.cacheMutex.RUnlock()
-	return primaryDB.api.Get(ctx, model)
+	return o.databaseClient(o.primaryDBName).Get(ctx, model)
 }
 
 // Create implements the API interface's Create function
 func (o *ovsdbClient) Create(models ...model.Model) ([]ovsdb.Operation, error) {
-	return o.primaryDB().api.Create(models...)
+	return o.databaseClient(o.primaryDBName).Create(models...)
+}
+
+// Snapshot implements the API interface's Snapshot function
+func (o *ovsdbClient) Snapshot(ctx context.Context) API {
+	return o.databaseClient(o.primaryDBName).Snapshot(ctx)
 }
 
 // List implements the API interface's List function
 func (o *ovsdbClient) List(ctx context.Context, result interface{}) error {
-	primaryDB := o.primaryDB()
-	waitForCacheConsistent(ctx, primaryDB, o.logger, o.primaryDBName)
-	defer primaryDB.cacheMutex.RUnlock()
-	return primaryDB.api.List(ctx, result)
+	return o.databaseClient(o.primaryDBName).List(ctx, result)
 }
 
 // Where implements the API interface's Where function
 func (o *ovsdbClient) Where(models ...model.Model) ConditionalAPI {
-	return o.primaryDB().api.Where(models...)
+	return o.databaseClient(o.primaryDBName).Where(models...)
 }
 
 // WhereAny implements the API interface's WhereAny function
 func (o *ovsdbClient) WhereAny(m model.Model, conditions ...model.Condition) ConditionalAPI {
-	return o.primaryDB().api.WhereAny(m, conditions...)
+	return o.databaseClient(o.primaryDBName).WhereAny(m, conditions...)
 }
 
 // WhereAll implements the API interface's WhereAll function
 func (o *ovsdbClient) WhereAll(m model.Model, conditions ...model.Condition) ConditionalAPI {
-	return o.primaryDB().api.WhereAll(m, conditions...)
+	return o.databaseClient(o.primaryDBName).WhereAll(m, conditions...)
+}
+
+// WhereReferencing implements the API interface's WhereReferencing function
+func (o *ovsdbClient) WhereReferencing(m model.Model, target model.Model, fields ...interface{}) ConditionalAPI {
+	return o.databaseClient(o.primaryDBName).WhereReferencing(m, target, fields...)
+}
+
+// WhereReferencedBy implements the API interface's WhereReferencedBy function
+func (o *ovsdbClient) WhereReferencedBy(m model.Model, field interface{}) ConditionalAPI {
+	return o.databaseClient(o.primaryDBName).WhereReferencedBy(m, field)
+}
+
+// OrderBy implements the API interface's OrderBy function
+func (o *ovsdbClient) OrderBy(m model.Model, field interface{}) OrderedAPI {
+	return o.databaseClient(o.primaryDBName).OrderBy(m, field)
+}
+
+// Assert implements the API interface's Assert function
+func (o *ovsdbClient) Assert(id string) ovsdb.Operation {
+	return o.databaseClient(o.primaryDBName).Assert(id)
 }
 
 // WhereCache implements the API interface's WhereCache function
 func (o *ovsdbClient) WhereCache(predicate interface{}) ConditionalAPI {
-	return o.primaryDB().api.WhereCache(predicate)
+	return o.databaseClient(o.primaryDBName).WhereCache(predicate)
 }
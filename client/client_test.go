@@ -10,6 +10,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -945,6 +946,12 @@ func TestSetOption(t *testing.T) {
 	err = o.SetOption(WithEndpoint("tcp::6640"))
 	require.NoError(t, err)
 
+	// the options are left unchanged if the databases cannot be added
+	err = o.SetOption(WithDatabaseModel(defDB))
+	assert.EqualError(t, err, "database Open_vSwitch is provided more than once")
+	assert.Empty(t, o.options.databaseModels)
+	assert.Len(t, o.databases, 1)
+
 	o.rpcClient = &rpc2.Client{}
 
 	err = o.SetOption(WithEndpoint("tcp::6641"))
@@ -1043,6 +1050,215 @@ func setLeader(t *testing.T, cli Client, row *serverdb.Database, isLeader bool)
 	assert.NoErrorf(t, err, "%+v", opErr)
 }
 
+func TestMultipleDatabases(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	serverDBModel, err := serverdb.FullDatabaseModel()
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	// a database can only be provided once
+	_, err = newOVSDBClient(defDB, WithDatabaseModel(defDB))
+	assert.EqualError(t, err, "database Open_vSwitch is provided more than once")
+
+	ovs, err := newOVSDBClient(defDB,
+		WithEndpoint(endpoint),
+		WithReconnect(2*time.Second, &backoff.ZeroBackOff{}))
+	require.NoError(t, err)
+	err = ovs.SetOption(WithDatabaseModel(serverDBModel))
+	require.NoError(t, err)
+	err = ovs.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(ovs.Close)
+	other, err := newOVSDBClient(serverDBModel, WithEndpoint(endpoint), WithDatabaseModel(defDB))
+	require.NoError(t, err)
+	err = other.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(other.Close)
+
+	_, err = ovs.Database("Unknown")
+	assert.Error(t, err)
+	ovsDB, err := ovs.Database(defDB.Name())
+	require.NoError(t, err)
+	assert.Equal(t, defSchema, ovsDB.Schema())
+	serverDB, err := ovs.Database(serverDBModel.Name())
+	require.NoError(t, err)
+	assert.Equal(t, serverdb.Schema(), serverDB.Schema())
+	otherOvsDB, err := other.Database(defDB.Name())
+	require.NoError(t, err)
+	otherServerDB, err := other.Database(serverDBModel.Name())
+	require.NoError(t, err)
+
+	_, err = ovsDB.MonitorAll(context.Background())
+	require.NoError(t, err)
+	cookie, err := serverDB.Monitor(context.Background(), serverDB.NewMonitor(WithTable(&serverdb.Database{})))
+	require.NoError(t, err)
+	assert.Equal(t, serverDBModel.Name(), cookie.DatabaseName)
+
+	create := func(db DatabaseClient, m model.Model) {
+		ops, err := db.Create(m)
+		require.NoError(t, err)
+		reply, err := db.Transact(context.Background(), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+	create(otherOvsDB, &Bridge{Name: "foo"})
+	create(otherServerDB, &serverdb.Database{Name: "foo", Model: serverdb.DatabaseModelStandalone})
+
+	// each database has its own cache, populated by its own monitors
+	require.Eventually(t, func() bool {
+		return len(ovsDB.Cache().Table("Bridge").Rows()) == 1 && len(serverDB.Cache().Table("Database").Rows()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ovs.Cache(), ovsDB.Cache())
+	assert.Nil(t, serverDB.Cache().Table("Bridge"))
+	databases := []serverdb.Database{}
+	err = serverDB.List(context.Background(), &databases)
+	require.NoError(t, err)
+	require.Len(t, databases, 1)
+	assert.Equal(t, "foo", databases[0].Name)
+
+	// the monitors of every database are restarted on reconnection
+	ovs.Disconnect()
+	create(otherOvsDB, &Bridge{Name: "bar"})
+	create(otherServerDB, &serverdb.Database{Name: "bar", Model: serverdb.DatabaseModelStandalone})
+	require.Eventually(t, func() bool {
+		return len(ovsDB.Cache().Table("Bridge").Rows()) == 2 && len(serverDB.Cache().Table("Database").Rows()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// canceling a monitor of a database does not affect the other ones
+	err = ovs.MonitorCancel(context.Background(), cookie)
+	require.NoError(t, err)
+	assert.True(t, hasMonitors(ovs.databases[defDB.Name()]))
+	assert.False(t, hasMonitors(ovs.databases[serverDBModel.Name()]))
+}
+
+func TestMonitorBlockingHandlerReadsCache(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	writer, err := newOVSDBClient(defDB, WithEndpoint(endpoint))
+	require.NoError(t, err)
+	err = writer.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(writer.Close)
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		ops, err := writer.Create(&Bridge{Name: name})
+		require.NoError(t, err)
+		reply, err := writer.Transact(context.Background(), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+
+	ovs, err := newOVSDBClient(defDB, WithEndpoint(endpoint))
+	require.NoError(t, err)
+	err = ovs.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(ovs.Close)
+
+	// the initial dump does not fit in the buffer of the handler, which
+	// reads the cache for every event
+	var mutex sync.Mutex
+	listed := []int{}
+	ovs.Cache().AddEventHandler(&cache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			bridges := []Bridge{}
+			err := ovs.List(context.Background(), &bridges)
+			assert.NoError(t, err)
+			mutex.Lock()
+			defer mutex.Unlock()
+			listed = append(listed, len(bridges))
+		},
+	}, cache.WithDeliveryMode(cache.DeliveryBlock), cache.WithBufferSize(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = ovs.Monitor(ctx, ovs.NewMonitor(WithTable(&Bridge{})))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(listed) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{4, 4, 4, 4}, listed)
+}
+
+func TestUpdateBlockingHandlerReadsCache(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	writer, err := newOVSDBClient(defDB, WithEndpoint(endpoint))
+	require.NoError(t, err)
+	err = writer.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(writer.Close)
+
+	ovs, err := newOVSDBClient(defDB, WithEndpoint(endpoint))
+	require.NoError(t, err)
+	err = ovs.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(ovs.Close)
+	_, err = ovs.Monitor(context.Background(), ovs.NewMonitor(WithTable(&Bridge{})))
+	require.NoError(t, err)
+
+	// the handler reads the cache for every event, once unblocked while
+	// the update does not fit in its buffer and another monitor waits to
+	// populate the cache
+	received := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	var mutex sync.Mutex
+	listed := []int{}
+	ovs.Cache().AddEventHandler(&cache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+			<-unblock
+			bridges := []Bridge{}
+			err := ovs.List(context.Background(), &bridges)
+			assert.NoError(t, err)
+			mutex.Lock()
+			defer mutex.Unlock()
+			listed = append(listed, len(bridges))
+		},
+	}, cache.WithDeliveryMode(cache.DeliveryBlock), cache.WithBufferSize(1))
+
+	ops, err := writer.Create(&Bridge{Name: "foo"}, &Bridge{Name: "bar"}, &Bridge{Name: "baz"}, &Bridge{Name: "qux"})
+	require.NoError(t, err)
+	reply, err := writer.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	<-received
+
+	monitored := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err := ovs.Monitor(ctx, ovs.NewMonitor(WithTable(&OpenvSwitch{})))
+		monitored <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	close(unblock)
+	require.NoError(t, <-monitored)
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(listed) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{4, 4, 4, 4}, listed)
+}
+
 func TestClientInactiveCheck(t *testing.T) {
 	var defSchema ovsdb.DatabaseSchema
 	err := json.Unmarshal([]byte(schema), &defSchema)
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"github.com/cenkalti/rpc2"
+)
+
+// requestWithSeq holds the arguments of a request along with the sequence
+// number it is sent with, which is its JSON-RPC id. It is needed to refer to
+// the request later on, like when canceling a transaction.
+type requestWithSeq struct {
+	args []interface{}
+	seq  uint64
+}
+
+// jsonCodec wraps the JSON-RPC codec of rpc2 to record the sequence number of
+// the requests sent with a *requestWithSeq argument
+type jsonCodec struct {
+	rpc2.Codec
+}
+
+// WriteRequest implements the rpc2.Codec interface. rpc2 writes a request
+// from Go() before returning, so the sequence number is set when it returns.
+func (c jsonCodec) WriteRequest(r *rpc2.Request, args interface{}) error {
+	if request, ok := args.(*requestWithSeq); ok {
+		request.seq = r.Seq
+		args = request.args
+	}
+	return c.Codec.WriteRequest(r, args)
+}
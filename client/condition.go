@@ -223,6 +223,178 @@ func newPredicateConditional(table string, cache *cache.TableCache, predicate in
 	}, nil
 }
 
+// referencingConditional matches the rows of a table that reference the row
+// matching a model, either by UUID or indexes, through the reverse references
+// indexed by the cache
+type referencingConditional struct {
+	tableName string
+	target    model.Model
+	columns   []string
+	cache     *cache.TableCache
+}
+
+func (c *referencingConditional) Table() string {
+	return c.tableName
+}
+
+// Returns the models that reference the row matching the target model
+func (c *referencingConditional) Matches() (map[string]model.Model, error) {
+	tableCache := c.cache.Table(c.tableName)
+	if tableCache == nil {
+		return nil, ErrNotFound
+	}
+	targetTable := c.cache.DatabaseModel().FindTable(reflect.TypeOf(c.target))
+	targetCache := c.cache.Table(targetTable)
+	if targetCache == nil {
+		return nil, ErrNotFound
+	}
+	uuid, _, err := targetCache.RowByModel(c.target)
+	if err != nil {
+		return nil, err
+	}
+	if uuid == "" {
+		// no row can reference a row missing from the cache
+		return map[string]model.Model{}, nil
+	}
+	return tableCache.RowsReferencing(targetTable, uuid, c.columns...), nil
+}
+
+// Generate returns a list of conditions that match, by _uuid equality, all the
+// models referencing the target model
+func (c *referencingConditional) Generate() ([][]ovsdb.Condition, error) {
+	models, err := c.Matches()
+	if err != nil {
+		return nil, err
+	}
+	return generateConditionsFromModels(c.cache.DatabaseModel(), models)
+}
+
+// newReferencingConditional creates a new referencingConditional
+func newReferencingConditional(table string, cache *cache.TableCache, m model.Model, target model.Model, fields ...interface{}) (Conditional, error) {
+	dbModel := cache.DatabaseModel()
+	targetTable := dbModel.FindTable(reflect.TypeOf(target))
+	if targetTable == "" {
+		return nil, &ErrWrongType{reflect.TypeOf(target), "Model not found in Database Model"}
+	}
+	info, err := dbModel.NewModelInfo(m)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		column, err := info.ColumnByPtr(field)
+		if err != nil {
+			return nil, err
+		}
+		toTable, err := referencedTable(info.Metadata.TableSchema.Column(column))
+		if err != nil {
+			return nil, fmt.Errorf("column %s of table %s: %w", column, table, err)
+		}
+		if toTable != targetTable {
+			return nil, fmt.Errorf("column %s of table %s does not reference table %s", column, table, targetTable)
+		}
+		columns = append(columns, column)
+	}
+	return &referencingConditional{
+		tableName: table,
+		target:    target,
+		columns:   columns,
+		cache:     cache,
+	}, nil
+}
+
+// referencedConditional matches the rows referenced from a column of the row
+// matching a model, either by UUID or indexes
+type referencedConditional struct {
+	tableName string
+	fromTable string
+	from      model.Model
+	column    string
+	cache     *cache.TableCache
+}
+
+func (c *referencedConditional) Table() string {
+	return c.tableName
+}
+
+// Returns the models referenced by the row matching the provided model
+func (c *referencedConditional) Matches() (map[string]model.Model, error) {
+	fromCache := c.cache.Table(c.fromTable)
+	if fromCache == nil || c.cache.Table(c.tableName) == nil {
+		return nil, ErrNotFound
+	}
+	uuid, _, err := fromCache.RowByModel(c.from)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]model.Model{}
+	if uuid == "" {
+		return found, nil
+	}
+	for u, m := range c.cache.RowsReferencedBy(c.fromTable, uuid, c.column)[c.tableName] {
+		found[u] = m
+	}
+	return found, nil
+}
+
+// Generate returns a list of conditions that match, by _uuid equality, all the
+// models referenced by the provided model
+func (c *referencedConditional) Generate() ([][]ovsdb.Condition, error) {
+	models, err := c.Matches()
+	if err != nil {
+		return nil, err
+	}
+	return generateConditionsFromModels(c.cache.DatabaseModel(), models)
+}
+
+// newReferencedConditional creates a new referencedConditional
+func newReferencedConditional(table string, cache *cache.TableCache, m model.Model, field interface{}) (Conditional, error) {
+	info, err := cache.DatabaseModel().NewModelInfo(m)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	toTable, err := referencedTable(info.Metadata.TableSchema.Column(column))
+	if err != nil {
+		return nil, fmt.Errorf("column %s of table %s: %w", column, table, err)
+	}
+	return &referencedConditional{
+		tableName: toTable,
+		fromTable: table,
+		from:      m,
+		column:    column,
+		cache:     cache,
+	}, nil
+}
+
+// referencedTable returns the table referenced by a column
+func referencedTable(columnSchema *ovsdb.ColumnSchema) (string, error) {
+	if columnSchema == nil || columnSchema.TypeObj == nil {
+		return "", fmt.Errorf("column does not reference any table")
+	}
+	var toTable string
+	for _, baseType := range []*ovsdb.BaseType{columnSchema.TypeObj.Key, columnSchema.TypeObj.Value} {
+		if baseType == nil || baseType.Type != ovsdb.TypeUUID {
+			continue
+		}
+		refTable, err := baseType.RefTable()
+		if err != nil || refTable == "" {
+			continue
+		}
+		if toTable != "" && toTable != refTable {
+			return "", fmt.Errorf("column references more than one table")
+		}
+		toTable = refTable
+	}
+	if toTable == "" {
+		return "", fmt.Errorf("column does not reference any table")
+	}
+	return toTable, nil
+}
+
 // errorConditional is a conditional that encapsulates an error
 // It is used to delay the reporting of errors from conditional creation to API method call
 type errorConditional struct {
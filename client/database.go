@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// DatabaseClient gives access to one of the databases of a Client. The
+// databases of a Client share its connection: they are connected, disconnected
+// and reconnected together, while each of them has its own schema, cache and
+// monitors that are re-established on reconnection.
+type DatabaseClient interface {
+	Name() string
+	Schema() ovsdb.DatabaseSchema
+	Cache() *cache.TableCache
+	Transact(context.Context, ...ovsdb.Operation) ([]ovsdb.OperationResult, error)
+	Monitor(context.Context, *Monitor) (MonitorCookie, error)
+	MonitorAll(context.Context) (MonitorCookie, error)
+	NewMonitor(...MonitorOption) *Monitor
+	NewTransaction() Transaction
+	OptimisticTransact(context.Context, backoff.BackOff, func(OptimisticTransaction) error) ([]ModelError, error)
+	API
+}
+
+// databaseClient is the DatabaseClient of a database of an ovsdbClient
+type databaseClient struct {
+	client *ovsdbClient
+	name   string
+	db     *database
+}
+
+// Database returns the DatabaseClient of the database with the provided name,
+// which is either the database of the model the client was created with or one
+// added with WithDatabaseModel
+func (o *ovsdbClient) Database(name string) (DatabaseClient, error) {
+	if _, ok := o.databases[name]; !ok {
+		return nil, fmt.Errorf("database %s is not part of the client", name)
+	}
+	return o.databaseClient(name), nil
+}
+
+func (o *ovsdbClient) databaseClient(name string) *databaseClient {
+	return &databaseClient{
+		client: o,
+		name:   name,
+		db:     o.databases[name],
+	}
+}
+
+// Name returns the name of the database
+func (d *databaseClient) Name() string {
+	return d.name
+}
+
+// Schema returns the DatabaseSchema of the database. It will be empty until
+// a connection has been established
+func (d *databaseClient) Schema() ovsdb.DatabaseSchema {
+	d.db.modelMutex.RLock()
+	defer d.db.modelMutex.RUnlock()
+	return d.db.model.Schema
+}
+
+// dbModel returns the DatabaseModel of the database
+func (d *databaseClient) dbModel() model.DatabaseModel {
+	d.db.modelMutex.RLock()
+	defer d.db.modelMutex.RUnlock()
+	return d.db.model
+}
+
+// Cache returns the TableCache of the database that is populated from ovsdb
+// update notifications. It will be nil until a connection has been
+// established, and empty unless you call Monitor
+func (d *databaseClient) Cache() *cache.TableCache {
+	d.db.cacheMutex.RLock()
+	defer d.db.cacheMutex.RUnlock()
+	return d.db.cache
+}
+
+// Transact performs the provided Operations on the database
+// RFC 7047 : transact
+func (d *databaseClient) Transact(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	return d.client.transactWhenConnected(ctx, d.name, operation...)
+}
+
+// Monitor will provide updates for a given table/column of the database
+// and populate its cache with them. Subsequent updates will be processed
+// by the Update Notifications
+// RFC 7047 : monitor
+func (d *databaseClient) Monitor(ctx context.Context, monitor *Monitor) (MonitorCookie, error) {
+	cookie := newMonitorCookie(d.name)
+	d.db.monitorsMutex.Lock()
+	defer d.db.monitorsMutex.Unlock()
+	return cookie, d.client.monitor(ctx, cookie, false, monitor)
+}
+
+// MonitorAll is a convenience method to monitor every table/column of the
+// database
+func (d *databaseClient) MonitorAll(ctx context.Context) (MonitorCookie, error) {
+	m := newMonitor()
+	for name := range d.db.model.Types() {
+		m.Tables = append(m.Tables, TableMonitor{Table: name})
+	}
+	return d.Monitor(ctx, m)
+}
+
+// NewMonitor creates a new Monitor of the database with the provided options
+func (d *databaseClient) NewMonitor(opts ...MonitorOption) *Monitor {
+	return newDatabaseMonitor(d.db, opts...)
+}
+
+// Get implements the API interface's Get function
+func (d *databaseClient) Get(ctx context.Context, model model.Model) error {
+	waitForCacheConsistent(ctx, d.db, d.client.logger, d.name)
+	defer d.db.cacheMutex.RUnlock()
+	return d.db.api.Get(ctx, model)
+}
+
+// Snapshot implements the API interface's Snapshot function
+func (d *databaseClient) Snapshot(ctx context.Context) API {
+	waitForCacheConsistent(ctx, d.db, d.client.logger, d.name)
+	defer d.db.cacheMutex.RUnlock()
+	return d.db.api.Snapshot(ctx)
+}
+
+// Create implements the API interface's Create function
+func (d *databaseClient) Create(models ...model.Model) ([]ovsdb.Operation, error) {
+	return d.db.api.Create(models...)
+}
+
+// List implements the API interface's List function
+func (d *databaseClient) List(ctx context.Context, result interface{}) error {
+	waitForCacheConsistent(ctx, d.db, d.client.logger, d.name)
+	defer d.db.cacheMutex.RUnlock()
+	return d.db.api.List(ctx, result)
+}
+
+// Where implements the API interface's Where function
+func (d *databaseClient) Where(models ...model.Model) ConditionalAPI {
+	return d.db.api.Where(models...)
+}
+
+// WhereAny implements the API interface's WhereAny function
+func (d *databaseClient) WhereAny(m model.Model, conditions ...model.Condition) ConditionalAPI {
+	return d.db.api.WhereAny(m, conditions...)
+}
+
+// WhereAll implements the API interface's WhereAll function
+func (d *databaseClient) WhereAll(m model.Model, conditions ...model.Condition) ConditionalAPI {
+	return d.db.api.WhereAll(m, conditions...)
+}
+
+// Assert implements the API interface's Assert function
+func (d *databaseClient) Assert(id string) ovsdb.Operation {
+	return d.db.api.Assert(id)
+}
+
+// WhereReferencing implements the API interface's WhereReferencing function
+func (d *databaseClient) WhereReferencing(m model.Model, target model.Model, fields ...interface{}) ConditionalAPI {
+	return d.db.api.WhereReferencing(m, target, fields...)
+}
+
+// WhereReferencedBy implements the API interface's WhereReferencedBy function
+func (d *databaseClient) WhereReferencedBy(m model.Model, field interface{}) ConditionalAPI {
+	return d.db.api.WhereReferencedBy(m, field)
+}
+
+// OrderBy implements the API interface's OrderBy function
+func (d *databaseClient) OrderBy(m model.Model, field interface{}) OrderedAPI {
+	return d.db.api.OrderBy(m, field)
+}
+
+// WhereCache implements the API interface's WhereCache function
+func (d *databaseClient) WhereCache(predicate interface{}) ConditionalAPI {
+	return d.db.api.WhereCache(predicate)
+}
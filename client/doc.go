@@ -111,6 +111,31 @@ cache element, an operation will be created matching on the "_uuid" column. The
 quite large depending on the cache size and the provided function. Most likely there is a way to express the
 same condition using Where() or WhereAll() which will be more efficient.
 
+References between rows can be followed with WhereReferencing() and WhereReferencedBy(). The cache keeps an index of
+the rows referencing each row through the columns with a refTable, so the referencing rows are found without
+scanning their table. WhereReferencing() matches the rows of the table of a model that reference another row,
+optionally through some of its fields only, while WhereReferencedBy() matches the rows referenced from a field:
+
+	ls := &LogicalSwitch{}
+	lsp := &LogicalSwitchPort{Name: "foo"}
+	err := ovs.WhereReferencing(ls, lsp, &ls.Ports).List(ctx, lsList)
+	ls = &LogicalSwitch{UUID: "myUUID"}
+	err = ovs.WhereReferencedBy(ls, &ls.Ports).List(ctx, lspList)
+
+Client indexes (see ClientDBModel's SetIndexes) speed up the search of the cache. An index of type
+model.OrderedIndexType, on a single integer, real or string column, also keeps the rows sorted by that column. It is
+used to evaluate range conditions (<, <=, > and >=) without scanning the table, and OrderBy() lists the rows in that
+order, a page at a time:
+
+	dbModel.SetIndexes(map[string][]model.ClientIndex{
+		"ACL": {{Type: model.OrderedIndexType, Columns: []model.ColumnKey{{Column: "priority"}}}},
+	})
+	acl := &ACL{}
+	aclList := []*ACL{}
+	err := ovs.OrderBy(acl, &acl.Priority).Descending().Limit(100).List(ctx, &aclList)
+	// the next page follows the last row of the previous one
+	err = ovs.OrderBy(acl, &acl.Priority).Descending().Limit(100).After(aclList[len(aclList)-1]).List(ctx, &next)
+
 Get
 
 Get() operation is a simple operation capable of retrieving one Model based on some of its schema indexes. E.g:
@@ -131,6 +156,35 @@ List() searches the cache and populates a slice of Models. It can be used direct
 	    	return strings.HasPrefix(ls.Name, "ext_")
 	}).List(lsList)
 
+Snapshot
+
+Each table of the cache is read on its own, so updates might be applied in between reads of different tables.
+Snapshot() returns an API reading from a copy of the cache that is consistent across tables and not updated any
+further. Taking it is cheap, as rows are only copied when the cache modifies them:
+
+	snapshot := ovs.Snapshot(ctx)
+	err := snapshot.Get(ctx, ls)
+	lspList := &[]LogicalSwitchPort{}
+	err = snapshot.WhereCache(func(lsp *LogicalSwitchPort) bool {
+		return slices.Contains(ls.Ports, lsp.UUID)
+	}).List(ctx, lspList)
+
+Generic API
+
+The functions List, Get, Create, Where, WhereAny, WhereAll, WhereCache, WhereReferencing, WhereReferencedBy and
+OrderBy are type-safe counterparts of the API methods, so that mismatching types are caught by the compiler. Together with
+model.NewCondition and model.NewMutation, which check that values have the type of the field, and OnAdd, OnUpdate
+and OnDelete, which register typed event handlers, they run through the same API and cache:
+
+	lsList, err := client.WhereCache(ovs, func(ls *LogicalSwitch) bool {
+		return strings.HasPrefix(ls.Name, "ext_")
+	}).List(ctx)
+	ls := &LogicalSwitch{}
+	ops, err := client.WhereAll(ovs, ls, model.NewCondition(&ls.Name, ovsdb.ConditionEqual, "foo")).Delete()
+	client.OnAdd(ovs.Cache(), func(ls *LogicalSwitch) {
+		fmt.Printf("Switch %s added", ls.Name)
+	})
+
 Create
 
 Create returns a list of operations to create the models provided. E.g:
@@ -160,5 +214,57 @@ Delete returns a list of operations needed to delete the matching rows. E.g:
 
 	ops, err := ovs.Where(...).Delete()
 
+Transaction
+
+NewTransaction returns a Transaction that collects the operations on models to be performed together, and maps the
+results back onto the models. Models created with an empty UUID are given a named UUID other models can refer to,
+which is replaced with the UUID of the inserted row on Commit. The errors of failed operations are returned as
+ModelErrors pointing at the model they were added for. E.g:
+
+	ls := &LogicalSwitch{Name: "foo"}
+	lsp := &LogicalSwitchPort{Name: "foo-port"}
+	txn := ovs.NewTransaction()
+	err := txn.Create(lsp)
+	ls.Ports = []string{lsp.UUID}
+	err = txn.Create(ls)
+	errs, err := txn.Commit(context.Background())
+	fmt.Printf("Port %s of switch %s created", ls.Ports[0], ls.UUID)
+
+Transact returns once the transaction is committed, which might be before the update notifications it causes reach
+the cache. A context returned by WaitForCache makes it wait until the rows changed by the transaction have been updated
+in the cache:
+
+	reply, err := ovs.Transact(client.WaitForCache(ctx), ops...)
+	err = ovs.Get(ctx, ls) // returns the updated switch
+
+When the context of Transact is done before the server replies, like when its deadline expires, the client sends a
+cancel request for the transaction and returns the error of the context. The server aborts the transaction unless it
+is already complete, so whether it was committed is not known.
+
+OptimisticTransact builds a transaction with a function that reads models from the cache through the
+OptimisticTransaction it is given. The transaction only succeeds if the rows read have not changed in the database by
+the time it is committed. Otherwise the function is run again once the cache reflects the changes, until the backoff
+stops and ErrConflict is returned:
+
+	errs, err := ovs.OptimisticTransact(ctx, nil, func(txn client.OptimisticTransaction) error {
+		ls := &LogicalSwitch{UUID: uuid}
+		if err := txn.Get(ctx, ls); err != nil {
+			return err
+		}
+		ls.ExternalIDs["count"] = strconv.Itoa(len(ls.Ports))
+		return txn.Where(ls).Update(ls, &ls.ExternalIDs)
+	})
+
+Multiple Databases
+
+The client interacts with the database of the model it is created with. Other databases of the same server can be
+accessed over the same connection by providing their models with WithDatabaseModel. Each of them has its own schema,
+cache and monitors, that are re-established when the client reconnects, and is accessed through Database():
+
+	ovs, _ := client.NewOVSDBClient(nbModel, client.WithDatabaseModel(sbModel))
+	sb, _ := ovs.Database("OVN_Southbound")
+	_, err := sb.MonitorAll(context.Background())
+	err = sb.List(context.Background(), &chassisList)
+
 */
 package client
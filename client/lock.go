@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// LockHandler is called whenever the ownership of a lock requested with Lock
+// or Steal changes. locked is true when the client has been granted the lock
+// and false when the client no longer owns it, either because it has been
+// stolen by another client, because it has been released with Unlock or
+// because the connection to the server has been lost. It is called from the
+// goroutine that processes the messages received from the server, so it must
+// not block.
+type LockHandler func(id string, locked bool)
+
+// lockRequest is a lock requested by the client
+type lockRequest struct {
+	// method is the method the lock was requested with, lock or steal, and
+	// is requested again with on reconnection
+	method string
+	owned  bool
+}
+
+// Lock requests the lock identified by id from the server and returns whether
+// it has been granted right away. Otherwise, the client is queued for the lock
+// and the LockHandler configured with WithLockHandler is called once the
+// server grants it. Requested locks are requested again when the client
+// reconnects to the server.
+// RFC 7047 : lock
+func (o *ovsdbClient) Lock(ctx context.Context, id string) (bool, error) {
+	return o.lock(ctx, "lock", id)
+}
+
+// Steal requests the lock identified by id from the server, preempting its
+// current owner if any. Stolen locks are stolen again when the client
+// reconnects to the server.
+// RFC 7047 : steal
+func (o *ovsdbClient) Steal(ctx context.Context, id string) error {
+	_, err := o.lock(ctx, "steal", id)
+	return err
+}
+
+// Unlock releases the lock identified by id, or cancels the pending request
+// for it, and stops requesting it on reconnection.
+// RFC 7047 : unlock
+func (o *ovsdbClient) Unlock(ctx context.Context, id string) error {
+	o.rpcMutex.RLock()
+	defer o.rpcMutex.RUnlock()
+
+	o.locksMutex.Lock()
+	lock, ok := o.locks[id]
+	delete(o.locks, id)
+	o.locksMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("lock %s has not been requested", id)
+	}
+	if lock.owned && o.options.lockHandler != nil {
+		o.options.lockHandler(id, false)
+	}
+
+	// the server releases all the locks of a client when it disconnects
+	if o.rpcClient == nil {
+		return nil
+	}
+	var reply struct{}
+	err := o.rpcClient.CallWithContext(ctx, "unlock", ovsdb.NewLockArgs(id), &reply)
+	if err != nil {
+		if err == rpc2.ErrShutdown {
+			return ErrNotConnected
+		}
+		return err
+	}
+	return nil
+}
+
+// HasLock returns whether the client currently owns the lock identified by id
+func (o *ovsdbClient) HasLock(id string) bool {
+	o.locksMutex.Lock()
+	defer o.locksMutex.Unlock()
+	lock, ok := o.locks[id]
+	return ok && lock.owned
+}
+
+func (o *ovsdbClient) lock(ctx context.Context, method, id string) (bool, error) {
+	o.rpcMutex.RLock()
+	defer o.rpcMutex.RUnlock()
+	if o.rpcClient == nil {
+		return false, ErrNotConnected
+	}
+
+	o.locksMutex.Lock()
+	if _, ok := o.locks[id]; ok {
+		o.locksMutex.Unlock()
+		return false, fmt.Errorf("lock %s has already been requested", id)
+	}
+	// the lock is tracked before sending the request so that a locked
+	// notification received right after the reply is not missed
+	o.locks[id] = &lockRequest{method: method}
+	o.locksMutex.Unlock()
+
+	locked, err := o.requestLock(ctx, method, id)
+	if err != nil {
+		o.locksMutex.Lock()
+		delete(o.locks, id)
+		o.locksMutex.Unlock()
+		return false, err
+	}
+	return locked, nil
+}
+
+// requestLock sends a lock or steal request for the provided lock to the
+// server. Assumes rpcMutex is held.
+func (o *ovsdbClient) requestLock(ctx context.Context, method, id string) (bool, error) {
+	var reply ovsdb.LockResult
+	err := o.rpcClient.CallWithContext(ctx, method, ovsdb.NewLockArgs(id), &reply)
+	if err != nil {
+		if err == rpc2.ErrShutdown {
+			return false, ErrNotConnected
+		}
+		return false, err
+	}
+	if reply.Locked {
+		o.setLockOwnership(id, true)
+	}
+	return reply.Locked, nil
+}
+
+// restartLocks requests again all the locks that were requested before
+// reconnecting, with the method they were requested with. Assumes rpcMutex
+// is held.
+func (o *ovsdbClient) restartLocks(ctx context.Context) error {
+	o.locksMutex.Lock()
+	methods := make(map[string]string, len(o.locks))
+	for id, lock := range o.locks {
+		methods[id] = lock.method
+	}
+	o.locksMutex.Unlock()
+
+	for id, method := range methods {
+		if _, err := o.requestLock(ctx, method, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseLocks flags all the locks as not owned after losing the connection
+// to the server
+func (o *ovsdbClient) releaseLocks() {
+	o.locksMutex.Lock()
+	var released []string
+	for id, lock := range o.locks {
+		if lock.owned {
+			lock.owned = false
+			released = append(released, id)
+		}
+	}
+	o.locksMutex.Unlock()
+
+	if o.options.lockHandler == nil {
+		return
+	}
+	for _, id := range released {
+		o.options.lockHandler(id, false)
+	}
+}
+
+// setLockOwnership updates the ownership of a requested lock and calls the
+// LockHandler if it changed
+func (o *ovsdbClient) setLockOwnership(id string, locked bool) {
+	o.locksMutex.Lock()
+	lock, ok := o.locks[id]
+	if !ok || lock.owned == locked {
+		o.locksMutex.Unlock()
+		return
+	}
+	lock.owned = locked
+	o.locksMutex.Unlock()
+
+	if o.options.lockHandler != nil {
+		o.options.lockHandler(id, locked)
+	}
+}
+
+// RFC 7047 : Section 4.1.9 : Locked Notification
+func (o *ovsdbClient) locked(args []interface{}) error {
+	id, err := lockNotificationID(args)
+	if err != nil {
+		return err
+	}
+	o.setLockOwnership(id, true)
+	return nil
+}
+
+// RFC 7047 : Section 4.1.10 : Stolen Notification
+func (o *ovsdbClient) stolen(args []interface{}) error {
+	id, err := lockNotificationID(args)
+	if err != nil {
+		return err
+	}
+	o.setLockOwnership(id, false)
+	return nil
+}
+
+func lockNotificationID(args []interface{}) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("lock notification should have 1 argument, got %d", len(args))
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("lock notification argument %v is not a string", args[0])
+	}
+	return id, nil
+}
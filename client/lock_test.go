@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lockEvent struct {
+	id     string
+	locked bool
+}
+
+func newLockTestClient(t *testing.T, endpoint string, opts ...Option) (*ovsdbClient, chan lockEvent) {
+	events := make(chan lockEvent, 10)
+	opts = append(opts,
+		WithEndpoint(endpoint),
+		WithLockHandler(func(id string, locked bool) {
+			events <- lockEvent{id, locked}
+		}),
+	)
+	ovs, err := newOVSDBClient(defDB, opts...)
+	require.NoError(t, err)
+	err = ovs.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(ovs.Close)
+	return ovs, events
+}
+
+func expectLockEvent(t *testing.T, events chan lockEvent, expected lockEvent) {
+	select {
+	case event := <-events:
+		assert.Equal(t, expected, event)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for lock event %+v", expected)
+	}
+}
+
+func assertLock(t *testing.T, ovs Client, id string) error {
+	op := ovs.Assert(id)
+	reply, err := ovs.Transact(context.Background(), op)
+	require.NoError(t, err)
+	opErrs, err := ovsdb.CheckOperationResults(reply, []ovsdb.Operation{op})
+	if len(opErrs) > 0 {
+		return opErrs[0]
+	}
+	return err
+}
+
+func TestClientLock(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	ctx := context.Background()
+	cli1, events1 := newLockTestClient(t, endpoint)
+	cli2, events2 := newLockTestClient(t, endpoint)
+
+	locked, err := cli1.Lock(ctx, "foo")
+	require.NoError(t, err)
+	assert.True(t, locked)
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+	assert.True(t, cli1.HasLock("foo"))
+	assert.NoError(t, assertLock(t, cli1, "foo"))
+
+	_, err = cli1.Lock(ctx, "foo")
+	assert.Error(t, err)
+
+	locked, err = cli2.Lock(ctx, "foo")
+	require.NoError(t, err)
+	assert.False(t, locked)
+	assert.False(t, cli2.HasLock("foo"))
+	err = assertLock(t, cli2, "foo")
+	assert.IsType(t, &ovsdb.NotOwner{}, err)
+
+	// cli2 is already queued for the lock so it has to unlock it first
+	err = cli2.Steal(ctx, "foo")
+	assert.Error(t, err)
+	err = cli2.Unlock(ctx, "foo")
+	require.NoError(t, err)
+	err = cli2.Steal(ctx, "foo")
+	require.NoError(t, err)
+	expectLockEvent(t, events2, lockEvent{"foo", true})
+	expectLockEvent(t, events1, lockEvent{"foo", false})
+	assert.True(t, cli2.HasLock("foo"))
+	assert.False(t, cli1.HasLock("foo"))
+	assert.NoError(t, assertLock(t, cli2, "foo"))
+
+	// cli1 is still queued for the lock and gets it back
+	err = cli2.Unlock(ctx, "foo")
+	require.NoError(t, err)
+	expectLockEvent(t, events2, lockEvent{"foo", false})
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+	assert.True(t, cli1.HasLock("foo"))
+	assert.False(t, cli2.HasLock("foo"))
+
+	err = cli2.Unlock(ctx, "foo")
+	assert.Error(t, err)
+}
+
+func TestClientLockReconnect(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	ctx := context.Background()
+	cli1, events1 := newLockTestClient(t, endpoint, WithReconnect(2*time.Second, &backoff.ZeroBackOff{}))
+	cli2, events2 := newLockTestClient(t, endpoint)
+
+	locked, err := cli1.Lock(ctx, "foo")
+	require.NoError(t, err)
+	assert.True(t, locked)
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+
+	locked, err = cli2.Lock(ctx, "foo")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	// the lock is handed over to cli2 on disconnection and cli1 queues for
+	// it again once reconnected
+	cli1.Disconnect()
+	expectLockEvent(t, events1, lockEvent{"foo", false})
+	expectLockEvent(t, events2, lockEvent{"foo", true})
+	require.Eventually(t, func() bool {
+		return cli1.Connected()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	err = cli2.Unlock(ctx, "foo")
+	require.NoError(t, err)
+	expectLockEvent(t, events2, lockEvent{"foo", false})
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+	assert.True(t, cli1.HasLock("foo"))
+}
+
+func TestClientLockReconnectSteal(t *testing.T) {
+	var defSchema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(schema), &defSchema)
+	require.NoError(t, err)
+	_, sock := newOVSDBServer(t, defDB, defSchema)
+	endpoint := fmt.Sprintf("unix:%s", sock)
+
+	ctx := context.Background()
+	cli1, events1 := newLockTestClient(t, endpoint, WithReconnect(2*time.Second, &backoff.ZeroBackOff{}))
+	cli2, events2 := newLockTestClient(t, endpoint)
+
+	err = cli1.Steal(ctx, "foo")
+	require.NoError(t, err)
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+
+	locked, err := cli2.Lock(ctx, "foo")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	// the lock is handed over to cli2 on disconnection and cli1 steals it
+	// again once reconnected
+	cli1.Disconnect()
+	expectLockEvent(t, events1, lockEvent{"foo", false})
+	expectLockEvent(t, events2, lockEvent{"foo", true})
+	expectLockEvent(t, events1, lockEvent{"foo", true})
+	expectLockEvent(t, events2, lockEvent{"foo", false})
+	assert.True(t, cli1.HasLock("foo"))
+	assert.False(t, cli2.HasLock("foo"))
+}
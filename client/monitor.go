@@ -30,9 +30,15 @@ func newMonitor() *Monitor {
 
 // NewMonitor creates a new Monitor with the provided options
 func (o *ovsdbClient) NewMonitor(opts ...MonitorOption) *Monitor {
+	return newDatabaseMonitor(o.primaryDB(), opts...)
+}
+
+// newDatabaseMonitor creates a new Monitor of the provided database with the
+// provided options
+func newDatabaseMonitor(db *database, opts ...MonitorOption) *Monitor {
 	m := newMonitor()
 	for _, opt := range opts {
-		err := opt(o, m)
+		err := opt(db, m)
 		if err != nil {
 			m.Errors = append(m.Errors, err)
 		}
@@ -41,7 +47,7 @@ func (o *ovsdbClient) NewMonitor(opts ...MonitorOption) *Monitor {
 }
 
 // MonitorOption adds Tables to a Monitor
-type MonitorOption func(o *ovsdbClient, m *Monitor) error
+type MonitorOption func(db *database, m *Monitor) error
 
 // MonitorCookie is the struct we pass to correlate from updates back to their
 // originating Monitor request.
@@ -68,8 +74,8 @@ type TableMonitor struct {
 	Fields []string
 }
 
-func newTableMonitor(o *ovsdbClient, m model.Model, conditions []model.Condition, fields []interface{}) (*TableMonitor, error) {
-	dbModel := o.primaryDB().model
+func newTableMonitor(db *database, m model.Model, conditions []model.Condition, fields []interface{}) (*TableMonitor, error) {
+	dbModel := db.model
 	tableName := dbModel.FindTable(reflect.TypeOf(m))
 	if tableName == "" {
 		return nil, fmt.Errorf("object of type %s is not part of the ClientDBModel", reflect.TypeOf(m))
@@ -97,8 +103,7 @@ func newTableMonitor(o *ovsdbClient, m model.Model, conditions []model.Condition
 		}
 		columns = append(columns, column)
 	}
-	db := o.databases[o.primaryDBName]
-	mmapper := db.model.Mapper
+	mmapper := dbModel.Mapper
 	for _, modelCond := range conditions {
 		ovsdbCond, err := mmapper.NewCondition(data, modelCond.Field, modelCond.Function, modelCond.Value)
 		if err != nil {
@@ -114,8 +119,8 @@ func newTableMonitor(o *ovsdbClient, m model.Model, conditions []model.Condition
 }
 
 func WithTable(m model.Model, fields ...interface{}) MonitorOption {
-	return func(o *ovsdbClient, monitor *Monitor) error {
-		tableMonitor, err := newTableMonitor(o, m, []model.Condition{}, fields)
+	return func(db *database, monitor *Monitor) error {
+		tableMonitor, err := newTableMonitor(db, m, []model.Condition{}, fields)
 		if err != nil {
 			return err
 		}
@@ -125,8 +130,8 @@ func WithTable(m model.Model, fields ...interface{}) MonitorOption {
 }
 
 func WithConditionalTable(m model.Model, conditions []model.Condition, fields ...interface{}) MonitorOption {
-	return func(o *ovsdbClient, monitor *Monitor) error {
-		tableMonitor, err := newTableMonitor(o, m, conditions, fields)
+	return func(db *database, monitor *Monitor) error {
+		tableMonitor, err := newTableMonitor(db, m, conditions, fields)
 		if err != nil {
 			return err
 		}
@@ -15,7 +15,7 @@ func TestWithTable(t *testing.T) {
 	m := newMonitor()
 	opt := WithTable(&OpenvSwitch{})
 
-	err = opt(client, m)
+	err = opt(client.primaryDB(), m)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(m.Tables))
@@ -44,7 +44,7 @@ func TestWithTableAndFields(t *testing.T) {
 	m := newMonitor()
 	ovs := OpenvSwitch{}
 	opt := WithTable(&ovs, &ovs.Bridges, &ovs.CurCfg)
-	err = opt(client, m)
+	err = opt(client.primaryDB(), m)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(m.Tables))
@@ -68,7 +68,7 @@ func TestWithTableAndFieldsAndConditions(t *testing.T) {
 	}
 
 	opt := WithConditionalTable(&bridge, conditions, &bridge.Name, &bridge.DatapathType)
-	err = opt(client, m)
+	err = opt(client.primaryDB(), m)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(m.Tables))
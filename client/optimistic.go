@@ -0,0 +1,309 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// defaultOptimisticRetries is the number of times OptimisticTransact runs a
+// conflicting transaction again when no backoff is provided
+const defaultOptimisticRetries = 5
+
+// ErrConflict is returned by OptimisticTransact when the rows read by the
+// transaction kept being changed concurrently
+var ErrConflict = errors.New("rows read by the transaction were changed concurrently")
+
+// OptimisticTransaction is a Transaction built by a function run by
+// OptimisticTransact. The rows read from the cache through it are guarded: the
+// transaction is only performed if they have not changed in the database when
+// it is committed.
+type OptimisticTransaction interface {
+	// Get retrieves a model from the cache, like API's Get, and guards its
+	// row
+	Get(context.Context, model.Model) error
+
+	// List populates a slice of models from the cache, like API's List, and
+	// guards their rows. Rows added to the table afterwards are not
+	// detected.
+	List(context.Context, interface{}) error
+
+	// WhereCache returns a CacheReader that lists the models of the cache
+	// matching the predicate, like API's WhereCache, and guards their rows
+	WhereCache(interface{}) CacheReader
+
+	// Create adds the operations needed to insert the models, like
+	// Transaction's Create
+	Create(...model.Model) error
+
+	// Where, WhereAny and WhereAll return a TransactionConditionalAPI, like
+	// Transaction's
+	Where(...model.Model) TransactionConditionalAPI
+	WhereAny(model.Model, ...model.Condition) TransactionConditionalAPI
+	WhereAll(model.Model, ...model.Condition) TransactionConditionalAPI
+}
+
+// CacheReader reads the models of the cache matching a condition
+type CacheReader interface {
+	// List populates a slice of the models matching the condition
+	List(context.Context, interface{}) error
+}
+
+// guard is a row read by an OptimisticTransaction
+type guard struct {
+	table string
+	uuid  string
+	// model is the state of the row when it was read
+	model model.Model
+	// columns are the monitored columns of the row
+	columns []string
+}
+
+// optimisticTransaction implements OptimisticTransaction
+type optimisticTransaction struct {
+	*transaction
+	// guards holds the rows read by the transaction, by table and uuid
+	guards map[string]map[string]*guard
+}
+
+// OptimisticTransact runs fn to build a transaction that is only performed if
+// the rows fn reads through it have not changed in the database in the
+// meantime. If they have, it waits for the cache to be updated with their
+// changes and runs fn again, for as long as backOff allows; ErrConflict is
+// returned once it no longer does. If backOff is nil, fn is run again
+// immediately up to 5 times. The errors of the failed operations, if any,
+// are returned like Transaction's Commit does.
+func (d *databaseClient) OptimisticTransact(ctx context.Context, backOff backoff.BackOff, fn func(OptimisticTransaction) error) ([]ModelError, error) {
+	if backOff == nil {
+		backOff = backoff.WithMaxRetries(&backoff.ZeroBackOff{}, defaultOptimisticRetries)
+	}
+	backOff.Reset()
+	for {
+		txn := &optimisticTransaction{
+			transaction: &transaction{db: d},
+			guards:      map[string]map[string]*guard{},
+		}
+		if err := fn(txn); err != nil {
+			return nil, err
+		}
+		errs, err := txn.commit(ctx)
+		conflicts := txn.conflicts(errs)
+		if len(conflicts) == 0 {
+			return errs, err
+		}
+		next := backOff.NextBackOff()
+		if next == backoff.Stop {
+			return errs, ErrConflict
+		}
+		if err := waitForConflicts(ctx, d.db, conflicts); err != nil {
+			return errs, err
+		}
+		select {
+		case <-ctx.Done():
+			return errs, fmt.Errorf("%w: while backing off from a conflict", ctx.Err())
+		case <-time.After(next):
+		}
+	}
+}
+
+// record guards the row of a model read from the cache
+func (t *optimisticTransaction) record(m model.Model) error {
+	dbModel := t.db.dbModel()
+	info, err := dbModel.NewModelInfo(m)
+	if err != nil {
+		return err
+	}
+	uuid, err := info.FieldByColumn("_uuid")
+	if err != nil {
+		return err
+	}
+	table := info.Metadata.TableName
+	if t.guards[table] == nil {
+		t.guards[table] = map[string]*guard{}
+	}
+	if _, ok := t.guards[table][uuid.(string)]; ok {
+		// the row was read before
+		return nil
+	}
+	t.guards[table][uuid.(string)] = &guard{
+		table: table,
+		uuid:  uuid.(string),
+		model: model.Clone(m),
+	}
+	return nil
+}
+
+// recordList guards the rows of a slice of models read from the cache
+func (t *optimisticTransaction) recordList(result interface{}) error {
+	resultVal := reflect.Indirect(reflect.ValueOf(result))
+	for i := 0; i < resultVal.Len(); i++ {
+		elem := resultVal.Index(i)
+		if elem.Kind() != reflect.Ptr {
+			elem = elem.Addr()
+		}
+		if err := t.record(elem.Interface().(model.Model)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get implements the OptimisticTransaction interface's Get function
+func (t *optimisticTransaction) Get(ctx context.Context, m model.Model) error {
+	if err := t.db.Get(ctx, m); err != nil {
+		return err
+	}
+	return t.record(m)
+}
+
+// List implements the OptimisticTransaction interface's List function
+func (t *optimisticTransaction) List(ctx context.Context, result interface{}) error {
+	if err := t.db.List(ctx, result); err != nil {
+		return err
+	}
+	return t.recordList(result)
+}
+
+// WhereCache implements the OptimisticTransaction interface's WhereCache
+// function
+func (t *optimisticTransaction) WhereCache(predicate interface{}) CacheReader {
+	return &optimisticCacheReader{transaction: t, predicate: predicate}
+}
+
+// optimisticCacheReader implements CacheReader
+type optimisticCacheReader struct {
+	transaction *optimisticTransaction
+	predicate   interface{}
+}
+
+// List implements the CacheReader interface's List function
+func (r *optimisticCacheReader) List(ctx context.Context, result interface{}) error {
+	db := r.transaction.db
+	waitForCacheConsistent(ctx, db.db, db.client.logger, db.name)
+	err := db.db.api.WhereCache(r.predicate).List(ctx, result)
+	db.db.cacheMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return r.transaction.recordList(result)
+}
+
+// commit adds a wait operation for each guarded row ahead of the operations of
+// the transaction and commits it
+func (t *optimisticTransaction) commit(ctx context.Context) ([]ModelError, error) {
+	dbModel := t.db.dbModel()
+	tables := monitoredTables(t.db.db)
+	timeout := 0
+	var ops []ovsdb.Operation
+	var models []model.Model
+	for table, guards := range t.guards {
+		for _, g := range guards {
+			info, err := dbModel.NewModelInfo(g.model)
+			if err != nil {
+				return nil, err
+			}
+			row := ovsdb.Row{}
+			for column := range info.Metadata.Fields {
+				if column == "_uuid" {
+					continue
+				}
+				if monitored, ok := tables[table]; ok && monitored.columns != nil && !monitored.columns[column] {
+					continue
+				}
+				value, err := info.FieldByColumn(column)
+				if err != nil {
+					return nil, err
+				}
+				ovsValue, err := ovsdb.NativeToOvs(info.Metadata.TableSchema.Column(column), value)
+				if err != nil {
+					return nil, err
+				}
+				row[column] = ovsValue
+				g.columns = append(g.columns, column)
+			}
+			if len(g.columns) == 0 {
+				continue
+			}
+			ops = append(ops, ovsdb.Operation{
+				Op:      ovsdb.OperationWait,
+				Table:   table,
+				Where:   []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: g.uuid})},
+				Until:   string(ovsdb.WaitConditionEqual),
+				Columns: g.columns,
+				Rows:    []ovsdb.Row{row},
+				Timeout: &timeout,
+			})
+			models = append(models, g.model)
+		}
+	}
+	t.ops = append(ops, t.ops...)
+	t.models = append(models, t.models...)
+	return t.Commit(ctx)
+}
+
+// conflicts returns the guarded rows that changed in the database
+func (t *optimisticTransaction) conflicts(errs []ModelError) []*guard {
+	var conflicts []*guard
+	for _, err := range errs {
+		if err.Operation().Op != ovsdb.OperationWait {
+			continue
+		}
+		for _, guards := range t.guards {
+			for _, g := range guards {
+				if g.model == err.Model {
+					conflicts = append(conflicts, g)
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// changed returns whether the row differs in the cache from when it was read
+func (g *guard) changed(tableCache *cache.TableCache) bool {
+	rowCache := tableCache.Table(g.table)
+	if rowCache == nil {
+		return false
+	}
+	row := rowCache.Row(g.uuid)
+	if row == nil {
+		return true
+	}
+	dbModel := tableCache.DatabaseModel()
+	info, err := dbModel.NewModelInfo(row)
+	if err != nil {
+		return false
+	}
+	readInfo, err := dbModel.NewModelInfo(g.model)
+	if err != nil {
+		return false
+	}
+	for _, column := range g.columns {
+		value, _ := info.FieldByColumn(column)
+		read, _ := readInfo.FieldByColumn(column)
+		if !ovsdb.EqualNativeValues(info.Metadata.TableSchema.Column(column), value, read) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForConflicts waits until the cache is updated with the changes of the
+// guarded rows that changed in the database
+func waitForConflicts(ctx context.Context, db *database, conflicts []*guard) error {
+	return waitForCache(ctx, db, func(tableCache *cache.TableCache) bool {
+		for _, g := range conflicts {
+			if !g.changed(tableCache) {
+				return false
+			}
+		}
+		return true
+	})
+}
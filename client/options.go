@@ -7,6 +7,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-logr/logr"
+	"github.com/ovn-org/libovsdb/model"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -29,6 +30,8 @@ type options struct {
 	metricNamespace       string // prometheus metric namespace
 	metricSubsystem       string // prometheus metric subsystem
 	inactivityTimeout     time.Duration
+	lockHandler           LockHandler
+	databaseModels        []model.ClientDBModel
 }
 
 type Option func(o *options) error
@@ -128,6 +131,17 @@ func WithInactivityCheck(inactivityTimeout, reconnectTimeout time.Duration,
 	}
 }
 
+// WithDatabaseModel adds the database of the provided model to the ones the
+// client interacts with. The database is accessed over the same connection as
+// the database of the model the client was created with, through
+// Client.Database. It can be used multiple times to add several databases.
+func WithDatabaseModel(clientDBModel model.ClientDBModel) Option {
+	return func(o *options) error {
+		o.databaseModels = append(o.databaseModels, clientDBModel)
+		return nil
+	}
+}
+
 // WithLogger allows setting a specific log sink. Otherwise, the default
 // go log package is used.
 func WithLogger(l *logr.Logger) Option {
@@ -137,6 +151,15 @@ func WithLogger(l *logr.Logger) Option {
 	}
 }
 
+// WithLockHandler sets the function to be called whenever the ownership of a
+// lock requested by the client changes
+func WithLockHandler(handler LockHandler) Option {
+	return func(o *options) error {
+		o.lockHandler = handler
+		return nil
+	}
+}
+
 // WithMetricsRegistry allows the user to specify a Prometheus metrics registry.
 // If supplied, the metrics as defined in metrics.go will be registered.
 func WithMetricsRegistry(r prometheus.Registerer) Option {
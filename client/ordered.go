@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+)
+
+// OrderedAPI lists the rows of a table ordered by the column of an ordered
+// client index (see model.OrderedIndexType), then by UUID, a page at a time
+type OrderedAPI interface {
+	// Descending lists the rows from the greatest value of the column down
+	Descending() OrderedAPI
+
+	// After lists the rows that follow the provided model in the order,
+	// typically the last one of the previous page. Its UUID and the value
+	// of the column must be set
+	After(model.Model) OrderedAPI
+
+	// Limit lists up to the provided number of rows
+	Limit(int) OrderedAPI
+
+	// List populates a slice of Models with the rows of the page, in order.
+	// Rows with no value for the column, like an empty optional, are not
+	// listed
+	List(ctx context.Context, result interface{}) error
+}
+
+// orderedAPI implements the OrderedAPI interface
+type orderedAPI struct {
+	cache  *cache.TableCache
+	table  string
+	column string
+	page   cache.Page
+	err    error
+}
+
+// Descending implements the OrderedAPI interface's Descending function
+func (a orderedAPI) Descending() OrderedAPI {
+	a.page.Descending = true
+	return a
+}
+
+// After implements the OrderedAPI interface's After function
+func (a orderedAPI) After(m model.Model) OrderedAPI {
+	a.page.After = m
+	return a
+}
+
+// Limit implements the OrderedAPI interface's Limit function
+func (a orderedAPI) Limit(limit int) OrderedAPI {
+	a.page.Limit = limit
+	return a
+}
+
+// List implements the OrderedAPI interface's List function
+func (a orderedAPI) List(ctx context.Context, result interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+	resultPtr := reflect.ValueOf(result)
+	if resultPtr.Type().Kind() != reflect.Ptr || resultPtr.Elem().Kind() != reflect.Slice {
+		return &ErrWrongType{resultPtr.Type(), "Expected pointer to slice of valid Models"}
+	}
+	resultVal := resultPtr.Elem()
+	elemType := resultVal.Type().Elem()
+	modelType := elemType
+	if elemType.Kind() != reflect.Ptr {
+		modelType = reflect.PtrTo(elemType)
+	}
+	if table := a.cache.DatabaseModel().FindTable(modelType); table != a.table {
+		return &ErrWrongType{resultPtr.Type(),
+			fmt.Sprintf("Table derived from input type (%s) does not match Table from OrderBy (%s)", table, a.table)}
+	}
+
+	tableCache := a.cache.Table(a.table)
+	if tableCache == nil {
+		return ErrNotFound
+	}
+	rows, err := tableCache.RowsInOrder(a.column, a.page)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		if elemType.Kind() != reflect.Ptr {
+			v = v.Elem()
+		}
+		resultVal.Set(reflect.Append(resultVal, v))
+	}
+	return nil
+}
+
+// newOrderedAPI returns a new OrderedAPI for the column of the field of the
+// model
+func newOrderedAPI(c *cache.TableCache, table string, m model.Model, field interface{}) OrderedAPI {
+	a := orderedAPI{cache: c, table: table}
+	info, err := c.DatabaseModel().NewModelInfo(m)
+	if err != nil {
+		a.err = err
+		return a
+	}
+	a.column, a.err = info.ColumnByPtr(field)
+	return a
+}
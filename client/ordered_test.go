@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+func TestAPIOrderBy(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	require.NoError(t, err)
+	db, err := model.NewClientDBModel("OVN_Northbound", map[string]model.Model{"Logical_Switch": &testLogicalSwitch{}, "Logical_Switch_Port": &testLogicalSwitchPort{}})
+	require.NoError(t, err)
+	db.SetIndexes(map[string][]model.ClientIndex{
+		"Logical_Switch_Port": {{Type: model.OrderedIndexType, Columns: []model.ColumnKey{{Column: "tag"}}}},
+	})
+	dbModel, errs := model.NewDatabaseModel(schema, db)
+	require.Empty(t, errs)
+
+	tags := []int{30, 10, 20}
+	ports := map[string]model.Model{}
+	for i, uuid := range []string{aUUID0, aUUID1, aUUID2} {
+		ports[uuid] = &testLogicalSwitchPort{UUID: uuid, Name: uuid, Tag: &tags[i]}
+	}
+	// ports with no tag are not listed
+	ports[aUUID3] = &testLogicalSwitchPort{UUID: aUUID3, Name: aUUID3}
+	tcache, err := cache.NewTableCache(dbModel, cache.Data{"Logical_Switch_Port": ports}, nil)
+	require.NoError(t, err)
+	api := newAPI(tcache, &discardLogger)
+
+	lsp := &testLogicalSwitchPort{}
+	var result []testLogicalSwitchPort
+	err = api.OrderBy(lsp, &lsp.Tag).List(context.Background(), &result)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, []string{aUUID1, aUUID2, aUUID0}, []string{result[0].UUID, result[1].UUID, result[2].UUID})
+
+	var page []*testLogicalSwitchPort
+	err = api.OrderBy(lsp, &lsp.Tag).Descending().Limit(2).List(context.Background(), &page)
+	require.NoError(t, err)
+	assert.Equal(t, []*testLogicalSwitchPort{ports[aUUID0].(*testLogicalSwitchPort), ports[aUUID2].(*testLogicalSwitchPort)}, page)
+	var next []*testLogicalSwitchPort
+	err = api.OrderBy(lsp, &lsp.Tag).Descending().Limit(2).After(page[1]).List(context.Background(), &next)
+	require.NoError(t, err)
+	assert.Equal(t, []*testLogicalSwitchPort{ports[aUUID1].(*testLogicalSwitchPort)}, next)
+
+	typed, err := OrderBy(api, lsp, &lsp.Tag).Limit(1).List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*testLogicalSwitchPort{ports[aUUID1].(*testLogicalSwitchPort)}, typed)
+
+	// the column must have an ordered index
+	err = api.OrderBy(lsp, &lsp.Name).List(context.Background(), &page)
+	assert.Error(t, err)
+	// the result must be of the type of the model
+	var switches []*testLogicalSwitch
+	err = api.OrderBy(lsp, &lsp.Tag).List(context.Background(), &switches)
+	assert.Error(t, err)
+}
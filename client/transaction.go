@@ -0,0 +1,349 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Transaction collects operations on models to be performed in a single
+// transaction. Instead of matching the results of Transact to the operations
+// they were created for, Commit fills in the UUIDs of the inserted models and
+// reports the failed operations along with the models they were added for.
+type Transaction interface {
+	// Create adds the operations needed to insert the models. A model with
+	// an empty UUID is given a named UUID that the other models of the
+	// transaction can use to refer to it until Commit replaces it with the
+	// UUID of the inserted row
+	Create(...model.Model) error
+
+	// Where returns a TransactionConditionalAPI that adds operations on the
+	// rows matching the models according to their indexes, like API's Where
+	Where(...model.Model) TransactionConditionalAPI
+
+	// WhereAny returns a TransactionConditionalAPI that adds operations on
+	// the rows matching any of the conditions, like API's WhereAny
+	WhereAny(model.Model, ...model.Condition) TransactionConditionalAPI
+
+	// WhereAll returns a TransactionConditionalAPI that adds operations on
+	// the rows matching all of the conditions, like API's WhereAll
+	WhereAll(model.Model, ...model.Condition) TransactionConditionalAPI
+
+	// Operations returns the operations added to the transaction
+	Operations() []ovsdb.Operation
+
+	// Commit performs the operations of the transaction. On success, the
+	// named UUIDs of the models of the transaction are replaced by the UUIDs
+	// of the inserted rows. If any of the operations fails, the errors of
+	// the failed operations are returned as ModelErrors
+	Commit(context.Context) ([]ModelError, error)
+}
+
+// TransactionConditionalAPI adds operations that apply to the rows selected by
+// a condition to a Transaction
+type TransactionConditionalAPI interface {
+	// Update adds the operations needed to update the selected rows
+	// according to the data in the model, like ConditionalAPI's Update
+	Update(model.Model, ...interface{}) error
+
+	// Mutate adds the operations needed to perform the mutations on the
+	// selected rows, like ConditionalAPI's Mutate
+	Mutate(model.Model, ...model.Mutation) error
+
+	// Delete adds the operations needed to delete the selected rows
+	Delete() error
+}
+
+// ModelError is the error of a failed operation of a Transaction
+type ModelError struct {
+	ovsdb.OperationError
+	// Model is the model the operation was added to the transaction for
+	Model model.Model
+}
+
+// Error implements the error interface
+func (e ModelError) Error() string {
+	op := e.Operation()
+	return fmt.Sprintf("%s operation on table %s for %T failed: %s", op.Op, op.Table, e.Model, e.OperationError.Error())
+}
+
+// Unwrap returns the error of the operation
+func (e ModelError) Unwrap() error {
+	return e.OperationError
+}
+
+// transaction implements Transaction
+type transaction struct {
+	db  *databaseClient
+	ops []ovsdb.Operation
+	// models holds, for each operation, the model it was added for
+	models []model.Model
+}
+
+// NewTransaction returns a new Transaction on the database
+func (d *databaseClient) NewTransaction() Transaction {
+	return &transaction{db: d}
+}
+
+// newNamedUUID returns a unique named UUID
+func newNamedUUID() string {
+	return "row_" + strings.ReplaceAll(uuid.NewString(), "-", "_")
+}
+
+func (t *transaction) add(m model.Model, ops []ovsdb.Operation) {
+	for range ops {
+		t.models = append(t.models, m)
+	}
+	t.ops = append(t.ops, ops...)
+}
+
+// Create implements the Transaction interface's Create function
+func (t *transaction) Create(models ...model.Model) error {
+	dbModel := t.db.dbModel()
+	for _, m := range models {
+		info, err := dbModel.NewModelInfo(m)
+		if err != nil {
+			return err
+		}
+		id, err := info.FieldByColumn("_uuid")
+		if err != nil {
+			return err
+		}
+		if id.(string) == "" {
+			if err := info.SetField("_uuid", newNamedUUID()); err != nil {
+				return err
+			}
+		}
+	}
+	ops, err := t.db.Create(models...)
+	if err != nil {
+		return err
+	}
+	for i, m := range models {
+		t.add(m, ops[i:i+1])
+	}
+	return nil
+}
+
+// Where implements the Transaction interface's Where function
+func (t *transaction) Where(models ...model.Model) TransactionConditionalAPI {
+	c := &transactionConditionalAPI{transaction: t}
+	for _, m := range models {
+		c.conditionals = append(c.conditionals, t.db.Where(m))
+		c.models = append(c.models, m)
+	}
+	return c
+}
+
+// WhereAny implements the Transaction interface's WhereAny function
+func (t *transaction) WhereAny(m model.Model, conditions ...model.Condition) TransactionConditionalAPI {
+	return &transactionConditionalAPI{
+		transaction:  t,
+		conditionals: []ConditionalAPI{t.db.WhereAny(m, conditions...)},
+		models:       []model.Model{m},
+	}
+}
+
+// WhereAll implements the Transaction interface's WhereAll function
+func (t *transaction) WhereAll(m model.Model, conditions ...model.Condition) TransactionConditionalAPI {
+	return &transactionConditionalAPI{
+		transaction:  t,
+		conditionals: []ConditionalAPI{t.db.WhereAll(m, conditions...)},
+		models:       []model.Model{m},
+	}
+}
+
+// Operations implements the Transaction interface's Operations function
+func (t *transaction) Operations() []ovsdb.Operation {
+	ops := make([]ovsdb.Operation, len(t.ops))
+	copy(ops, t.ops)
+	return ops
+}
+
+// Commit implements the Transaction interface's Commit function
+func (t *transaction) Commit(ctx context.Context) ([]ModelError, error) {
+	results, err := t.db.Transact(ctx, t.Operations()...)
+	if err != nil {
+		return nil, err
+	}
+	opErrs, err := ovsdb.CheckOperationResults(results, t.ops)
+	if err != nil {
+		var errs []ModelError
+		for _, opErr := range opErrs {
+			for i := range t.ops {
+				if opErr.Operation() == &t.ops[i] {
+					errs = append(errs, ModelError{OperationError: opErr, Model: t.models[i]})
+					break
+				}
+			}
+		}
+		return errs, err
+	}
+
+	// fill in the UUIDs of the inserted rows and replace the named UUIDs the
+	// models of the transaction refer to
+	dbModel := t.db.dbModel()
+	uuids := map[string]string{}
+	for i, op := range t.ops {
+		if op.Op != ovsdb.OperationInsert {
+			continue
+		}
+		if op.UUIDName != "" {
+			uuids[op.UUIDName] = results[i].UUID.GoUUID
+		}
+		info, err := dbModel.NewModelInfo(t.models[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := info.SetField("_uuid", results[i].UUID.GoUUID); err != nil {
+			return nil, err
+		}
+	}
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+	resolved := map[model.Model]bool{}
+	for _, m := range t.models {
+		if resolved[m] {
+			continue
+		}
+		resolved[m] = true
+		info, err := dbModel.NewModelInfo(m)
+		if err != nil {
+			return nil, err
+		}
+		for column, columnSchema := range info.Metadata.TableSchema.Columns {
+			value, err := info.FieldByColumn(column)
+			if err != nil {
+				// the column is not part of the model
+				continue
+			}
+			if err := info.SetField(column, resolveNamedUUIDs(columnSchema, value, uuids)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+// resolveNamedUUIDs returns the native value of a column with the named UUIDs
+// it holds replaced by the UUIDs they were assigned
+func resolveNamedUUIDs(column *ovsdb.ColumnSchema, value interface{}, uuids map[string]string) interface{} {
+	if column.TypeObj == nil || column.TypeObj.Key == nil {
+		return value
+	}
+	keyIsUUID := column.TypeObj.Key.Type == ovsdb.TypeUUID
+	valueIsUUID := column.TypeObj.Value != nil && column.TypeObj.Value.Type == ovsdb.TypeUUID
+	if !keyIsUUID && !valueIsUUID {
+		return value
+	}
+	resolve := func(v reflect.Value) reflect.Value {
+		if id, ok := uuids[v.String()]; ok {
+			return reflect.ValueOf(id).Convert(v.Type())
+		}
+		return v
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return resolve(v).Interface()
+	case reflect.Ptr:
+		if v.IsNil() {
+			return value
+		}
+		resolved := reflect.New(v.Elem().Type())
+		resolved.Elem().Set(resolve(v.Elem()))
+		return resolved.Interface()
+	case reflect.Slice, reflect.Array:
+		var resolved reflect.Value
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return value
+			}
+			resolved = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		} else {
+			resolved = reflect.New(v.Type()).Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			resolved.Index(i).Set(resolve(v.Index(i)))
+		}
+		return resolved.Interface()
+	case reflect.Map:
+		if v.IsNil() {
+			return value
+		}
+		resolved := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, val := iter.Key(), iter.Value()
+			if keyIsUUID {
+				key = resolve(key)
+			}
+			if valueIsUUID {
+				val = resolve(val)
+			}
+			resolved.SetMapIndex(key, val)
+		}
+		return resolved.Interface()
+	}
+	return value
+}
+
+// transactionConditionalAPI implements TransactionConditionalAPI
+type transactionConditionalAPI struct {
+	transaction *transaction
+	// conditionals holds the conditional APIs selecting the rows, and models
+	// the model each of them was created for
+	conditionals []ConditionalAPI
+	models       []model.Model
+}
+
+// Update implements the TransactionConditionalAPI interface's Update function
+func (c *transactionConditionalAPI) Update(m model.Model, fields ...interface{}) error {
+	var ops []ovsdb.Operation
+	for _, conditional := range c.conditionals {
+		conditionalOps, err := conditional.Update(m, fields...)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, conditionalOps...)
+	}
+	c.transaction.add(m, ops)
+	return nil
+}
+
+// Mutate implements the TransactionConditionalAPI interface's Mutate function
+func (c *transactionConditionalAPI) Mutate(m model.Model, mutations ...model.Mutation) error {
+	var ops []ovsdb.Operation
+	for _, conditional := range c.conditionals {
+		conditionalOps, err := conditional.Mutate(m, mutations...)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, conditionalOps...)
+	}
+	c.transaction.add(m, ops)
+	return nil
+}
+
+// Delete implements the TransactionConditionalAPI interface's Delete function
+func (c *transactionConditionalAPI) Delete() error {
+	ops := make([][]ovsdb.Operation, len(c.conditionals))
+	for i, conditional := range c.conditionals {
+		var err error
+		ops[i], err = conditional.Delete()
+		if err != nil {
+			return err
+		}
+	}
+	for i := range ops {
+		c.transaction.add(c.models[i], ops[i])
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveNamedUUIDs(t *testing.T) {
+	uuids := map[string]string{
+		"foo": "8cfbad15-2bb5-4a12-9a2d-7af5b21e1e85",
+		"bar": "a5a3c4d8-76d5-4d1b-9c8f-c0b6d2a7c52f",
+	}
+	foo := uuids["foo"]
+	bar := uuids["bar"]
+	named := "foo"
+	other := "baz"
+	tests := []struct {
+		name     string
+		column   string
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "uuid",
+			column:   `{"type": "uuid"}`,
+			value:    "foo",
+			expected: foo,
+		},
+		{
+			name:     "unknown uuid",
+			column:   `{"type": "uuid"}`,
+			value:    "baz",
+			expected: "baz",
+		},
+		{
+			name:     "optional uuid",
+			column:   `{"type": {"key": "uuid", "min": 0, "max": 1}}`,
+			value:    &named,
+			expected: &foo,
+		},
+		{
+			name:     "unset optional uuid",
+			column:   `{"type": {"key": "uuid", "min": 0, "max": 1}}`,
+			value:    (*string)(nil),
+			expected: (*string)(nil),
+		},
+		{
+			name:     "uuid set",
+			column:   `{"type": {"key": "uuid", "min": 0, "max": "unlimited"}}`,
+			value:    []string{"foo", "baz", "bar"},
+			expected: []string{foo, "baz", bar},
+		},
+		{
+			name:     "uuid array",
+			column:   `{"type": {"key": "uuid", "min": 2, "max": 2}}`,
+			value:    [2]string{"foo", "bar"},
+			expected: [2]string{foo, bar},
+		},
+		{
+			name:     "uuid keys",
+			column:   `{"type": {"key": "uuid", "value": "string", "min": 0, "max": "unlimited"}}`,
+			value:    map[string]string{"foo": "bar", "baz": "foo"},
+			expected: map[string]string{foo: "bar", "baz": "foo"},
+		},
+		{
+			name:     "uuid values",
+			column:   `{"type": {"key": "integer", "value": "uuid", "min": 0, "max": "unlimited"}}`,
+			value:    map[int]string{1: "foo", 2: "baz"},
+			expected: map[int]string{1: foo, 2: "baz"},
+		},
+		{
+			name:     "string",
+			column:   `{"type": "string"}`,
+			value:    "foo",
+			expected: "foo",
+		},
+		{
+			name:     "optional string",
+			column:   `{"type": {"key": "string", "min": 0, "max": 1}}`,
+			value:    &other,
+			expected: &other,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var column ovsdb.ColumnSchema
+			err := json.Unmarshal([]byte(tt.column), &column)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resolveNamedUUIDs(&column, tt.value, uuids))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// TypedConditionalAPI is a ConditionalAPI for the models of type T
+type TypedConditionalAPI[T any] interface {
+	// List returns the models of the cache matching the condition
+	List(ctx context.Context) ([]*T, error)
+
+	// Mutate returns the operations needed to perform the mutations on the
+	// matching rows, like ConditionalAPI's Mutate
+	Mutate(m *T, mutations ...model.Mutation) ([]ovsdb.Operation, error)
+
+	// Update returns the operations needed to update the matching rows with
+	// the values of the model, like ConditionalAPI's Update
+	Update(m *T, fields ...interface{}) ([]ovsdb.Operation, error)
+
+	// Delete returns the operations needed to delete the matching rows
+	Delete() ([]ovsdb.Operation, error)
+
+	// Wait returns the operations needed to wait for the matching rows to
+	// hold the values of the model, like ConditionalAPI's Wait
+	Wait(until ovsdb.WaitCondition, timeout *int, m *T, fields ...interface{}) ([]ovsdb.Operation, error)
+}
+
+// typedConditionalAPI implements TypedConditionalAPI on top of a
+// ConditionalAPI
+type typedConditionalAPI[T any] struct {
+	cond ConditionalAPI
+}
+
+// List implements the TypedConditionalAPI interface's List function
+func (a typedConditionalAPI[T]) List(ctx context.Context) ([]*T, error) {
+	result := []*T{}
+	if err := a.cond.List(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Mutate implements the TypedConditionalAPI interface's Mutate function
+func (a typedConditionalAPI[T]) Mutate(m *T, mutations ...model.Mutation) ([]ovsdb.Operation, error) {
+	return a.cond.Mutate(m, mutations...)
+}
+
+// Update implements the TypedConditionalAPI interface's Update function
+func (a typedConditionalAPI[T]) Update(m *T, fields ...interface{}) ([]ovsdb.Operation, error) {
+	return a.cond.Update(m, fields...)
+}
+
+// Delete implements the TypedConditionalAPI interface's Delete function
+func (a typedConditionalAPI[T]) Delete() ([]ovsdb.Operation, error) {
+	return a.cond.Delete()
+}
+
+// Wait implements the TypedConditionalAPI interface's Wait function
+func (a typedConditionalAPI[T]) Wait(until ovsdb.WaitCondition, timeout *int, m *T, fields ...interface{}) ([]ovsdb.Operation, error) {
+	return a.cond.Wait(until, timeout, m, fields...)
+}
+
+// List returns all the models of type T in the cache
+func List[T any](ctx context.Context, a API) ([]*T, error) {
+	result := []*T{}
+	if err := a.List(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Get returns the model of type T in the cache matching the UUID or the
+// indexes set in the provided one, or ErrNotFound
+func Get[T any](ctx context.Context, a API, m *T) (*T, error) {
+	result := new(T)
+	model.CloneInto(m, result)
+	if err := a.Get(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Create returns the operations needed to add the models of type T to the
+// database, like API's Create
+func Create[T any](a API, models ...*T) ([]ovsdb.Operation, error) {
+	converted := make([]model.Model, 0, len(models))
+	for _, m := range models {
+		converted = append(converted, m)
+	}
+	return a.Create(converted...)
+}
+
+// Where returns a TypedConditionalAPI for the rows matching the UUID or the
+// indexes of any of the provided models, like API's Where
+func Where[T any](a API, models ...*T) TypedConditionalAPI[T] {
+	converted := make([]model.Model, 0, len(models))
+	for _, m := range models {
+		converted = append(converted, m)
+	}
+	return typedConditionalAPI[T]{cond: a.Where(converted...)}
+}
+
+// WhereAny returns a TypedConditionalAPI for the rows matching any of the
+// conditions, like API's WhereAny
+func WhereAny[T any](a API, m *T, conditions ...model.Condition) TypedConditionalAPI[T] {
+	return typedConditionalAPI[T]{cond: a.WhereAny(m, conditions...)}
+}
+
+// WhereAll returns a TypedConditionalAPI for the rows matching all of the
+// conditions, like API's WhereAll
+func WhereAll[T any](a API, m *T, conditions ...model.Condition) TypedConditionalAPI[T] {
+	return typedConditionalAPI[T]{cond: a.WhereAll(m, conditions...)}
+}
+
+// WhereCache returns a TypedConditionalAPI for the rows of the cache the
+// predicate returns true for, like API's WhereCache
+func WhereCache[T any](a API, predicate func(*T) bool) TypedConditionalAPI[T] {
+	return typedConditionalAPI[T]{cond: a.WhereCache(predicate)}
+}
+
+// WhereReferencing returns a TypedConditionalAPI for the rows of type T that
+// reference the row matching the target model, like API's WhereReferencing
+func WhereReferencing[T any](a API, m *T, target model.Model, fields ...interface{}) TypedConditionalAPI[T] {
+	return typedConditionalAPI[T]{cond: a.WhereReferencing(m, target, fields...)}
+}
+
+// WhereReferencedBy returns a TypedConditionalAPI for the rows of type T
+// referenced from a column of the row matching the model, like API's
+// WhereReferencedBy
+func WhereReferencedBy[T any](a API, m model.Model, field interface{}) TypedConditionalAPI[T] {
+	return typedConditionalAPI[T]{cond: a.WhereReferencedBy(m, field)}
+}
+
+// TypedOrderedAPI is an OrderedAPI for the models of type T
+type TypedOrderedAPI[T any] interface {
+	// Descending lists the rows from the greatest value of the column down
+	Descending() TypedOrderedAPI[T]
+
+	// After lists the rows that follow the provided model in the order,
+	// like OrderedAPI's After
+	After(m *T) TypedOrderedAPI[T]
+
+	// Limit lists up to the provided number of rows
+	Limit(limit int) TypedOrderedAPI[T]
+
+	// List returns the models of the page, in order
+	List(ctx context.Context) ([]*T, error)
+}
+
+// typedOrderedAPI implements TypedOrderedAPI on top of an OrderedAPI
+type typedOrderedAPI[T any] struct {
+	ordered OrderedAPI
+}
+
+// Descending implements the TypedOrderedAPI interface's Descending function
+func (a typedOrderedAPI[T]) Descending() TypedOrderedAPI[T] {
+	return typedOrderedAPI[T]{ordered: a.ordered.Descending()}
+}
+
+// After implements the TypedOrderedAPI interface's After function
+func (a typedOrderedAPI[T]) After(m *T) TypedOrderedAPI[T] {
+	return typedOrderedAPI[T]{ordered: a.ordered.After(m)}
+}
+
+// Limit implements the TypedOrderedAPI interface's Limit function
+func (a typedOrderedAPI[T]) Limit(limit int) TypedOrderedAPI[T] {
+	return typedOrderedAPI[T]{ordered: a.ordered.Limit(limit)}
+}
+
+// List implements the TypedOrderedAPI interface's List function
+func (a typedOrderedAPI[T]) List(ctx context.Context) ([]*T, error) {
+	result := []*T{}
+	if err := a.ordered.List(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// OrderBy returns a TypedOrderedAPI listing the models of type T ordered by a
+// field, like API's OrderBy
+func OrderBy[T any](a API, m *T, field interface{}) TypedOrderedAPI[T] {
+	return typedOrderedAPI[T]{ordered: a.OrderBy(m, field)}
+}
+
+// OnAdd registers a function called with the models of type T added to the
+// cache
+func OnAdd[T any](c *cache.TableCache, f func(*T), opts ...cache.EventHandlerOption) {
+	c.AddEventHandler(&cache.TypedEventHandlerFuncs[T]{AddFunc: f}, opts...)
+}
+
+// OnUpdate registers a function called with the models of type T updated in
+// the cache
+func OnUpdate[T any](c *cache.TableCache, f func(old, new *T), opts ...cache.EventHandlerOption) {
+	c.AddEventHandler(&cache.TypedEventHandlerFuncs[T]{UpdateFunc: f}, opts...)
+}
+
+// OnDelete registers a function called with the models of type T deleted from
+// the cache
+func OnDelete[T any](c *cache.TableCache, f func(*T), opts ...cache.EventHandlerOption) {
+	c.AddEventHandler(&cache.TypedEventHandlerFuncs[T]{DeleteFunc: f}, opts...)
+}
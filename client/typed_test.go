@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+func TestTypedAPI(t *testing.T) {
+	lsp0 := &testLogicalSwitchPort{
+		UUID:        aUUID2,
+		Name:        "lsp0",
+		Type:        "foo",
+		ExternalIds: map[string]string{"foo": "bar"},
+	}
+	lsp1 := &testLogicalSwitchPort{
+		UUID:        aUUID3,
+		Name:        "lsp1",
+		Type:        "bar",
+		ExternalIds: map[string]string{"foo": "baz"},
+	}
+	ls := &testLogicalSwitch{
+		UUID:  aUUID0,
+		Name:  "ls0",
+		Ports: []string{aUUID3},
+	}
+	tcache := apiTestCache(t, cache.Data{
+		"Logical_Switch": map[string]model.Model{aUUID0: ls},
+		"Logical_Switch_Port": map[string]model.Model{
+			aUUID2: lsp0,
+			aUUID3: lsp1,
+		},
+	})
+	api := newAPI(tcache, &discardLogger)
+
+	t.Run("List", func(t *testing.T) {
+		ports, err := List[testLogicalSwitchPort](context.Background(), api)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*testLogicalSwitchPort{lsp0, lsp1}, ports)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		port, err := Get(context.Background(), api, &testLogicalSwitchPort{Name: "lsp1"})
+		require.NoError(t, err)
+		assert.Equal(t, lsp1, port)
+		_, err = Get(context.Background(), api, &testLogicalSwitchPort{Name: "lsp2"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("WhereCache", func(t *testing.T) {
+		ports, err := WhereCache(api, func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Type == "bar"
+		}).List(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []*testLogicalSwitchPort{lsp1}, ports)
+	})
+
+	t.Run("WhereAll", func(t *testing.T) {
+		lsp := &testLogicalSwitchPort{}
+		ports, err := WhereAll(api, lsp,
+			model.NewCondition(&lsp.Type, ovsdb.ConditionEqual, "foo"),
+			model.NewCondition(&lsp.ExternalIds, ovsdb.ConditionIncludes, map[string]string{"foo": "bar"}),
+		).List(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []*testLogicalSwitchPort{lsp0}, ports)
+	})
+
+	t.Run("WhereReferencing", func(t *testing.T) {
+		switches, err := WhereReferencing(api, &testLogicalSwitch{}, lsp1).List(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []*testLogicalSwitch{ls}, switches)
+
+		ports, err := WhereReferencedBy[testLogicalSwitchPort](api, ls, &ls.Ports).List(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []*testLogicalSwitchPort{lsp1}, ports)
+	})
+
+	t.Run("operations", func(t *testing.T) {
+		// the operations are the same as the ones of the API
+		lsp := &testLogicalSwitchPort{Name: "lsp0", Addresses: []string{"foo"}}
+		ops, err := Where(api, lsp).Update(lsp, &lsp.Addresses)
+		require.NoError(t, err)
+		expected, err := api.Where(lsp).Update(lsp, &lsp.Addresses)
+		require.NoError(t, err)
+		assert.Equal(t, expected, ops)
+
+		ops, err = Where(api, lsp).Mutate(lsp,
+			model.NewMutation(&lsp.Addresses, ovsdb.MutateOperationInsert, []string{"bar"}),
+			model.NewMapKeysDeletion(&lsp.ExternalIds, "foo"),
+		)
+		require.NoError(t, err)
+		expected, err = api.Where(lsp).Mutate(lsp,
+			model.Mutation{Field: &lsp.Addresses, Mutator: ovsdb.MutateOperationInsert, Value: []string{"bar"}},
+			model.Mutation{Field: &lsp.ExternalIds, Mutator: ovsdb.MutateOperationDelete, Value: []string{"foo"}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, expected, ops)
+
+		ops, err = Where(api, lsp).Delete()
+		require.NoError(t, err)
+		expected, err = api.Where(lsp).Delete()
+		require.NoError(t, err)
+		assert.Equal(t, expected, ops)
+
+		ops, err = Create(api, &testLogicalSwitchPort{Name: "lsp2"})
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, ovsdb.OperationInsert, ops[0].Op)
+		assert.Equal(t, "Logical_Switch_Port", ops[0].Table)
+	})
+}
+
+func TestTypedEventHandlers(t *testing.T) {
+	tcache := apiTestCache(t, nil)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go tcache.Run(stopCh)
+
+	added := make(chan *testLogicalSwitchPort, 1)
+	deleted := make(chan *testLogicalSwitchPort, 1)
+	OnAdd(tcache, func(lsp *testLogicalSwitchPort) { added <- lsp })
+	OnDelete(tcache, func(lsp *testLogicalSwitchPort) { deleted <- lsp })
+	// the events of other tables are not delivered
+	OnAdd(tcache, func(ls *testLogicalSwitch) { t.Errorf("unexpected switch %v", ls) })
+
+	lsp := &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp0"}
+	row := ovsdb.Row{"name": "lsp0"}
+	err := tcache.Populate2(ovsdb.TableUpdates2{
+		"Logical_Switch_Port": {aUUID2: &ovsdb.RowUpdate2{Insert: &row}},
+	})
+	require.NoError(t, err)
+	select {
+	case got := <-added:
+		assert.Equal(t, lsp, got)
+	case <-time.After(1 * time.Second):
+		t.Fatal("add event not delivered")
+	}
+
+	err = tcache.Populate2(ovsdb.TableUpdates2{
+		"Logical_Switch_Port": {aUUID2: &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}}},
+	})
+	require.NoError(t, err)
+	select {
+	case got := <-deleted:
+		assert.Equal(t, lsp, got)
+	case <-time.After(1 * time.Second):
+		t.Fatal("delete event not delivered")
+	}
+}
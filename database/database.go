@@ -1,6 +1,9 @@
 package database
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
@@ -10,7 +13,7 @@ import (
 type Database interface {
 	CreateDatabase(database string, model ovsdb.DatabaseSchema) error
 	Exists(database string) bool
-	NewTransaction(database string) Transaction
+	NewTransaction(database string, opts ...TransactionOption) Transaction
 	Commit(database string, id uuid.UUID, update Update) error
 	CheckIndexes(database string, table string, m model.Model) error
 	List(database, table string, conditions ...ovsdb.Condition) (map[string]model.Model, error)
@@ -24,10 +27,70 @@ type Transaction interface {
 	Transact(operations ...ovsdb.Operation) ([]*ovsdb.OperationResult, Update)
 }
 
+// TransactionOptions holds the settings that a Database honors while
+// processing a transaction
+type TransactionOptions struct {
+	// LockOwner reports whether the issuer of the transaction owns the
+	// provided lock. Assert operations on locks not owned by the issuer fail
+	// with a "not owner" error.
+	LockOwner func(lock string) bool
+	// Context is canceled when the issuer of the transaction gives up on
+	// it. Operations that block, like wait, then fail with a "canceled"
+	// error.
+	Context context.Context
+	// Start is the time the transaction was first issued, that the timeouts
+	// of its wait operations count from. It defaults to the time the
+	// transaction is processed.
+	Start time.Time
+	// WaitBlocked, if set, is called by a wait operation that is neither
+	// satisfied nor timed out, with the table it waits on and the time it
+	// times out at, which is zero if it has no timeout. Instead of waiting,
+	// the operation then fails, and it is up to the issuer to process the
+	// transaction again once the table changes or the operation times out.
+	// Otherwise the operation polls the database until either happens.
+	WaitBlocked func(table string, deadline time.Time)
+}
+
+// TransactionOption sets an option of a transaction
+type TransactionOption func(*TransactionOptions)
+
+// WithLockOwner sets the function used to verify the ownership of locks
+// asserted by the transaction
+func WithLockOwner(owner func(lock string) bool) TransactionOption {
+	return func(o *TransactionOptions) {
+		o.LockOwner = owner
+	}
+}
+
+// WithContext sets the context that cancels the transaction
+func WithContext(ctx context.Context) TransactionOption {
+	return func(o *TransactionOptions) {
+		o.Context = ctx
+	}
+}
+
+// WithStart sets the time the transaction was first issued
+func WithStart(start time.Time) TransactionOption {
+	return func(o *TransactionOptions) {
+		o.Start = start
+	}
+}
+
+// WithWaitBlocked sets the function called by the wait operations that would
+// block the transaction
+func WithWaitBlocked(blocked func(table string, deadline time.Time)) TransactionOption {
+	return func(o *TransactionOptions) {
+		o.WaitBlocked = blocked
+	}
+}
+
 // Update abstracts an update that can be committed to a database
 type Update interface {
 	GetUpdatedTables() []string
 	ForEachModelUpdate(table string, do func(uuid string, old, new model.Model) error) error
 	ForEachRowUpdate(table string, do func(uuid string, row ovsdb.RowUpdate2) error) error
 	ForReferenceUpdates(do func(references References) error) error
+	// GetComment returns the comments provided with comment operations by
+	// the transaction the update results from, separated by new lines
+	GetComment() string
 }
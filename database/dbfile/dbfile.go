@@ -0,0 +1,155 @@
+package dbfile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/updates"
+)
+
+// ReadCache reads a database file into a new TableCache. Only the tables of
+// the client model are read. An incomplete record at the end of the file is
+// ignored.
+func ReadCache(r io.Reader, clientDBModel model.ClientDBModel) (*cache.TableCache, error) {
+	reader := NewReader(r)
+	dbModel, err := readDatabaseModel(reader, clientDBModel)
+	if err != nil {
+		return nil, err
+	}
+	data := cache.Data{}
+	for table := range dbModel.Types() {
+		data[table] = map[string]model.Model{}
+	}
+	get := func(table, uuid string) (model.Model, error) {
+		return data[table][uuid], nil
+	}
+	err = replay(reader, func(t *Transaction) error {
+		mu, err := t.ModelUpdates(dbModel, get)
+		if err != nil {
+			return err
+		}
+		for _, table := range mu.GetUpdatedTables() {
+			_ = mu.ForEachModelUpdate(table, func(uuid string, old, new model.Model) error {
+				if new == nil {
+					delete(data[table], uuid)
+				} else {
+					data[table][uuid] = new
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewTableCache(dbModel, data, nil)
+}
+
+// ReadDatabase reads a database file into a new in-memory database, named
+// after the client model. Only the tables of the client model are read. An
+// incomplete record at the end of the file is ignored.
+func ReadDatabase(r io.Reader, clientDBModel model.ClientDBModel) (database.Database, error) {
+	reader := NewReader(r)
+	dbModel, err := readDatabaseModel(reader, clientDBModel)
+	if err != nil {
+		return nil, err
+	}
+	name := clientDBModel.Name()
+	db := inmemory.NewDatabase(map[string]model.ClientDBModel{name: clientDBModel})
+	if err := db.CreateDatabase(name, dbModel.Schema); err != nil {
+		return nil, err
+	}
+	err = replay(reader, func(t *Transaction) error {
+		return ApplyTransaction(db, name, dbModel, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func readDatabaseModel(reader *Reader, clientDBModel model.ClientDBModel) (model.DatabaseModel, error) {
+	schema, err := reader.ReadSchema()
+	if err != nil {
+		return model.DatabaseModel{}, fmt.Errorf("failed to read schema: %w", err)
+	}
+	if schema.Name != clientDBModel.Name() {
+		return model.DatabaseModel{}, fmt.Errorf("file holds database %s, expected %s", schema.Name, clientDBModel.Name())
+	}
+	dbModel, errs := model.NewDatabaseModel(*schema, clientDBModel)
+	if len(errs) > 0 {
+		return model.DatabaseModel{}, fmt.Errorf("failed to create DatabaseModel: %#+v", errs)
+	}
+	return dbModel, nil
+}
+
+// replay reads the transaction records until the end of the file, or until an
+// incomplete record, and applies them
+func replay(reader *Reader, apply func(*Transaction) error) error {
+	for {
+		t, err := reader.ReadTransaction()
+		if err == io.EOF || err == ErrTruncated {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := apply(t); err != nil {
+			return fmt.Errorf("failed to apply record at offset %d: %w", reader.Offset(), err)
+		}
+	}
+}
+
+// ApplyTransaction commits a transaction record to a database, keeping track
+// of the references between its rows. The record is applied as it is: it
+// already holds the rows deleted by garbage collection, and the referential
+// integrity of the rows was checked when it was written.
+func ApplyTransaction(db database.Database, name string, dbModel model.DatabaseModel, t *Transaction) error {
+	get := func(table, uuid string) (model.Model, error) {
+		return db.Get(name, table, uuid)
+	}
+	mu, err := t.ModelUpdates(dbModel, get)
+	if err != nil {
+		return err
+	}
+	refs, err := updates.TrackReferences(dbModel, db, mu)
+	if err != nil {
+		return err
+	}
+	return db.Commit(name, uuid.New(), updates.NewDatabaseUpdate(mu, refs))
+}
+
+// WriteCache writes the rows of a TableCache to w as a database file
+func WriteCache(w io.Writer, tableCache *cache.TableCache) error {
+	list := func(table string) (map[string]model.Model, error) {
+		return tableCache.Table(table).Rows(), nil
+	}
+	return write(w, tableCache.DatabaseModel(), list)
+}
+
+// WriteDatabase writes the rows of a database to w as a database file
+func WriteDatabase(w io.Writer, db database.Database, name string, dbModel model.DatabaseModel) error {
+	list := func(table string) (map[string]model.Model, error) {
+		return db.List(name, table)
+	}
+	return write(w, dbModel, list)
+}
+
+func write(w io.Writer, dbModel model.DatabaseModel, list func(table string) (map[string]model.Model, error)) error {
+	t, err := NewSnapshotTransaction(dbModel, list)
+	if err != nil {
+		return err
+	}
+	writer := NewWriter(w)
+	if _, err := writer.WriteSchema(dbModel.Schema); err != nil {
+		return err
+	}
+	_, err = writer.WriteTransaction(t)
+	return err
+}
@@ -0,0 +1,236 @@
+package dbfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/model"
+
+	. "github.com/ovn-org/libovsdb/test"
+)
+
+// testFile returns a database file as written by ovsdb-server, with records
+// both in the original format and in the diff format
+func testFile(t *testing.T, fooUUID, barUUID, managerUUID string) []byte {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	schema, err := json.Marshal(dbModel.Schema)
+	require.NoError(t, err)
+	file := DatabaseFileRecord(string(schema))
+	for _, record := range []string{
+		`{"Bridge":{"` + fooUUID + `":{"name":"foo","external_ids":["map",[["foo","bar"],["baz","quux"]]]},"` + barUUID + `":{"name":"bar"}},"Manager":{"` + managerUUID + `":{"target":"ptcp:6640"}},"Open_vSwitch":{"` + uuid.NewString() + `":{"bridges":["set",[["uuid","` + fooUUID + `"],["uuid","` + barUUID + `"]]],"manager_options":["uuid","` + managerUUID + `"]}},"_date":1700000000000,"_comment":"ovs-vsctl: add-br foo"}`,
+		`{"Bridge":{"` + fooUUID + `":{"datapath_type":"netdev","external_ids":["map",[["foo","bar"]]]}},"_date":1700000000001}`,
+		`{"Bridge":{"` + fooUUID + `":{"external_ids":["map",[["foo","bar"],["qux","quux"]]]}},"_date":1700000000002,"_is_diff":true}`,
+	} {
+		file += DatabaseFileRecord(record)
+	}
+	return []byte(file)
+}
+
+// ovsdbServerFile is a database file written by ovsdb-server
+const ovsdbServerFile = `OVSDB JSON 88 2d1eceaecf1ded085730f8b8726b1f72fc67315d
+{"name":"Test","version":"1.0.0","tables":{"T":{"columns":{"name":{"type":"string"}}}}}
+OVSDB JSON 108 6beb0e398e7ee138e8f5848d6b73901b39758dd8
+{"T":{"2f4f1b0e-0c4e-4c11-9d1a-7c6a1d3c5e01":{"name":"foo"}},"_date":1700000000000,"_comment":"insert foo"}
+OVSDB JSON 100 cd11f63f8fdd5a3c08fcca8aba578883fa29271e
+{"T":{"2f4f1b0e-0c4e-4c11-9d1a-7c6a1d3c5e01":{"name":"bar"}},"_date":1700000000001,"_is_diff":true}
+`
+
+func TestReader(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte(ovsdbServerFile)))
+	schema, err := reader.ReadSchema()
+	require.NoError(t, err)
+	assert.Equal(t, "Test", schema.Name)
+	assert.Equal(t, int64(88+len("OVSDB JSON 88 2d1eceaecf1ded085730f8b8726b1f72fc67315d\n")), reader.Offset())
+
+	transaction, err := reader.ReadTransaction()
+	require.NoError(t, err)
+	assert.Equal(t, "insert foo", transaction.Comment)
+	assert.False(t, transaction.IsDiff)
+	transaction, err = reader.ReadTransaction()
+	require.NoError(t, err)
+	assert.True(t, transaction.IsDiff)
+	assert.Equal(t, int64(len(ovsdbServerFile)), reader.Offset())
+
+	_, err = reader.ReadTransaction()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestWriter(t *testing.T) {
+	var transaction Transaction
+	err := json.Unmarshal([]byte(`{"T":{"2f4f1b0e-0c4e-4c11-9d1a-7c6a1d3c5e01":{"name":"foo"}},"_date":1700000000000}`), &transaction)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	n, err := NewWriter(&buf).WriteTransaction(&transaction)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), n)
+
+	// records are written as ovsdb-server writes them
+	data, err := json.Marshal(transaction)
+	require.NoError(t, err)
+	assert.Equal(t, DatabaseFileRecord(string(data)), buf.String())
+}
+
+func TestTransactionJSON(t *testing.T) {
+	fooUUID := uuid.NewString()
+	record := `{"Bridge":{"` + fooUUID + `":null},"_comment":"delete foo","_date":1700000000000,"_is_diff":true}`
+	var transaction Transaction
+	err := json.Unmarshal([]byte(record), &transaction)
+	require.NoError(t, err)
+	assert.Equal(t, "delete foo", transaction.Comment)
+	assert.Equal(t, int64(1700000000000), transaction.Date)
+	assert.True(t, transaction.IsDiff)
+	require.Contains(t, transaction.Tables["Bridge"], fooUUID)
+	assert.Nil(t, transaction.Tables["Bridge"][fooUUID])
+
+	data, err := json.Marshal(transaction)
+	require.NoError(t, err)
+	assert.JSONEq(t, record, string(data))
+}
+
+func TestReadCache(t *testing.T) {
+	fooUUID := uuid.NewString()
+	barUUID := uuid.NewString()
+	data := testFile(t, fooUUID, barUUID, uuid.NewString())
+	expected := map[string]model.Model{
+		fooUUID: &BridgeType{
+			UUID:         fooUUID,
+			Name:         "foo",
+			DatapathType: "netdev",
+			ExternalIds:  map[string]string{"qux": "quux"},
+		},
+		barUUID: &BridgeType{
+			UUID: barUUID,
+			Name: "bar",
+		},
+	}
+
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	tableCache, err := ReadCache(bytes.NewReader(data), dbModel.Client())
+	require.NoError(t, err)
+	assert.Equal(t, expected, tableCache.Table("Bridge").Rows())
+	assert.Len(t, tableCache.Table("Open_vSwitch").Rows(), 1)
+
+	// an incomplete record at the end of the file is ignored
+	truncated := append(data, []byte("OVSDB JSON 100 0123456789abcdef0123456789abcdef01234567\n{\"Bridge\":")...)
+	tableCache, err = ReadCache(bytes.NewReader(truncated), dbModel.Client())
+	require.NoError(t, err)
+	assert.Equal(t, expected, tableCache.Table("Bridge").Rows())
+
+	// the tables that are not part of the client model are not read
+	clientDBModel, err := model.NewClientDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &BridgeType{}})
+	require.NoError(t, err)
+	tableCache, err = ReadCache(bytes.NewReader(data), clientDBModel)
+	require.NoError(t, err)
+	assert.Equal(t, expected, tableCache.Table("Bridge").Rows())
+	assert.Empty(t, tableCache.Table("Open_vSwitch").Rows())
+
+	// the file must hold the database of the client model
+	clientDBModel, err = model.NewClientDBModel("Unknown", map[string]model.Model{"Bridge": &BridgeType{}})
+	require.NoError(t, err)
+	_, err = ReadCache(bytes.NewReader(data), clientDBModel)
+	assert.Error(t, err)
+
+	// clustered databases are not supported
+	_, err = ReadCache(bytes.NewReader([]byte("OVSDB CLUSTER 2 0123456789abcdef0123456789abcdef01234567\n{}\n")), dbModel.Client())
+	assert.Error(t, err)
+}
+
+func TestReadDatabase(t *testing.T) {
+	fooUUID := uuid.NewString()
+	managerUUID := uuid.NewString()
+	data := testFile(t, fooUUID, uuid.NewString(), managerUUID)
+
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db, err := ReadDatabase(bytes.NewReader(data), dbModel.Client())
+	require.NoError(t, err)
+	bridge, err := db.Get("Open_vSwitch", "Bridge", fooUUID)
+	require.NoError(t, err)
+	assert.Equal(t, &BridgeType{
+		UUID:         fooUUID,
+		Name:         "foo",
+		DatapathType: "netdev",
+		ExternalIds:  map[string]string{"qux": "quux"},
+	}, bridge)
+
+	// references between rows are tracked
+	refs, err := db.GetReferences("Open_vSwitch", "Manager", managerUUID)
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+}
+
+func TestReadDatabasePartialModel(t *testing.T) {
+	managerUUID := uuid.NewString()
+	data := testFile(t, uuid.NewString(), uuid.NewString(), managerUUID)
+
+	// the records are applied as they are, without collecting the rows
+	// whose references are not part of the model
+	clientDBModel, err := model.NewClientDBModel("Open_vSwitch", map[string]model.Model{
+		"Bridge":  &BridgeType{},
+		"Manager": &ManagerType{},
+	})
+	require.NoError(t, err)
+	db, err := ReadDatabase(bytes.NewReader(data), clientDBModel)
+	require.NoError(t, err)
+	manager, err := db.Get("Open_vSwitch", "Manager", managerUUID)
+	require.NoError(t, err)
+	assert.Equal(t, &ManagerType{UUID: managerUUID, Target: "ptcp:6640"}, manager)
+}
+
+func TestWriteCache(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	data := testFile(t, uuid.NewString(), uuid.NewString(), uuid.NewString())
+	tableCache, err := ReadCache(bytes.NewReader(data), dbModel.Client())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = WriteCache(&buf, tableCache)
+	require.NoError(t, err)
+
+	// the rows are written as a single transaction
+	reader := NewReader(bytes.NewReader(buf.Bytes()))
+	_, err = reader.ReadSchema()
+	require.NoError(t, err)
+	transaction, err := reader.ReadTransaction()
+	require.NoError(t, err)
+	assert.Len(t, transaction.Tables["Bridge"], 2)
+	_, err = reader.ReadTransaction()
+	assert.ErrorIs(t, err, io.EOF)
+
+	written, err := ReadCache(bytes.NewReader(buf.Bytes()), dbModel.Client())
+	require.NoError(t, err)
+	assert.Equal(t, tableCache.Table("Bridge").Rows(), written.Table("Bridge").Rows())
+	assert.Equal(t, tableCache.Table("Open_vSwitch").Rows(), written.Table("Open_vSwitch").Rows())
+	assert.Equal(t, tableCache.Table("Manager").Rows(), written.Table("Manager").Rows())
+}
+
+func TestWriteDatabase(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	data := testFile(t, uuid.NewString(), uuid.NewString(), uuid.NewString())
+	db, err := ReadDatabase(bytes.NewReader(data), dbModel.Client())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = WriteDatabase(&buf, db, "Open_vSwitch", dbModel)
+	require.NoError(t, err)
+
+	written, err := ReadDatabase(bytes.NewReader(buf.Bytes()), dbModel.Client())
+	require.NoError(t, err)
+	for _, table := range []string{"Bridge", "Manager", "Open_vSwitch"} {
+		expected, err := db.List("Open_vSwitch", table)
+		require.NoError(t, err)
+		actual, err := written.List("Open_vSwitch", table)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
@@ -0,0 +1,20 @@
+/*
+Package dbfile reads and writes database files in the OVSDB standalone
+format, the format of the files created by ovsdb-tool and ovsdb-server.
+
+A database file is a log of records. Each record is made of a header line,
+with the magic "OVSDB JSON", the length and the SHA-1 digest of the record,
+followed by the record itself in JSON. The first record is the schema of the
+database and each of the following records is a transaction that inserts,
+modifies or deletes rows of the database.
+
+Database files can be read into a cache.TableCache or an in-memory
+database.Database, and written back from them:
+
+	f, err := os.Open("conf.db")
+	...
+	tableCache, err := dbfile.ReadCache(f, clientDBModel)
+	...
+	err = dbfile.WriteCache(w, tableCache)
+*/
+package dbfile
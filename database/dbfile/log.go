@@ -0,0 +1,159 @@
+package dbfile
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+const (
+	// magic is the magic of the header of the records of a database file in
+	// the OVSDB standalone format
+	magic = "OVSDB JSON"
+	// clusteredMagic is the magic of the header of the records of a database
+	// file in the OVSDB clustered format
+	clusteredMagic = "OVSDB CLUSTER"
+)
+
+// ErrTruncated is returned when the last record of a database file is
+// incomplete, usually as a result of a crash while it was being written. The
+// records before it are valid.
+var ErrTruncated = errors.New("truncated record")
+
+// Reader reads the records of a database file
+type Reader struct {
+	reader *bufio.Reader
+	offset int64
+}
+
+// NewReader returns a Reader that reads records from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{reader: bufio.NewReader(r)}
+}
+
+// Offset returns the offset of the end of the last record read
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// ReadSchema reads the schema record, which is the first record of a database
+// file
+func (r *Reader) ReadSchema() (*ovsdb.DatabaseSchema, error) {
+	data, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema record: %w", err)
+	}
+	return &schema, nil
+}
+
+// ReadTransaction reads the next transaction record. Returns io.EOF when
+// there are no more records, and ErrTruncated if the record is incomplete.
+func (r *Reader) ReadTransaction() (*Transaction, error) {
+	data, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	var t Transaction
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid transaction record: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *Reader) read() (json.RawMessage, error) {
+	header, err := r.reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			if header == "" {
+				return nil, io.EOF
+			}
+			return nil, ErrTruncated
+		}
+		return nil, err
+	}
+	length, digest, err := parseHeader(strings.TrimSuffix(header, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("record at offset %d: %w", r.offset, err)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrTruncated
+		}
+		return nil, err
+	}
+	sum := sha1.Sum(data)
+	if hex.EncodeToString(sum[:]) != digest {
+		return nil, fmt.Errorf("record at offset %d: digest mismatch", r.offset)
+	}
+	r.offset += int64(len(header)) + int64(length)
+	return data, nil
+}
+
+func parseHeader(header string) (int, string, error) {
+	if strings.HasPrefix(header, clusteredMagic) {
+		return 0, "", fmt.Errorf("clustered databases are not supported")
+	}
+	if !strings.HasPrefix(header, magic+" ") {
+		return 0, "", fmt.Errorf("invalid header %q", header)
+	}
+	fields := strings.Fields(strings.TrimPrefix(header, magic))
+	if len(fields) != 2 {
+		return 0, "", fmt.Errorf("invalid header %q", header)
+	}
+	length, err := strconv.Atoi(fields[0])
+	if err != nil || length < 0 {
+		return 0, "", fmt.Errorf("invalid length in header %q", header)
+	}
+	return length, fields[1], nil
+}
+
+// Writer writes the records of a database file
+type Writer struct {
+	writer io.Writer
+}
+
+// NewWriter returns a Writer that writes records to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{writer: w}
+}
+
+// WriteSchema writes the schema record, which must be the first record of a
+// database file, and returns the number of bytes written
+func (w *Writer) WriteSchema(schema ovsdb.DatabaseSchema) (int, error) {
+	return w.write(schema)
+}
+
+// WriteTransaction writes a transaction record and returns the number of
+// bytes written
+func (w *Writer) WriteTransaction(t *Transaction) (int, error) {
+	return w.write(t)
+}
+
+func (w *Writer) write(record interface{}) (int, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return 0, err
+	}
+	// as with ovsdb-server, the length and the digest of the record cover
+	// the new line that ends it
+	data = append(data, '\n')
+	sum := sha1.Sum(data)
+	header := fmt.Sprintf("%s %d %s\n", magic, len(data), hex.EncodeToString(sum[:]))
+	buf := make([]byte, 0, len(header)+len(data))
+	buf = append(buf, header...)
+	buf = append(buf, data...)
+	return w.writer.Write(buf)
+}
@@ -0,0 +1,188 @@
+package dbfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/updates"
+)
+
+// Transaction is a transaction record of a database file. It holds, for each
+// table, the rows changed by the transaction: the columns of inserted rows,
+// the columns changed in modified rows, and nil for deleted rows. If IsDiff is
+// set, the changed columns of modified rows hold the difference between the
+// old and the new values, as described for update2 notifications in
+// ovsdb-server(7), instead of the new values.
+type Transaction struct {
+	Tables map[string]map[string]*ovsdb.Row
+	// Date is the time of the transaction in milliseconds since the epoch
+	Date    int64
+	Comment string
+	IsDiff  bool
+}
+
+// MarshalJSON marshals a transaction record
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	record := make(map[string]interface{}, len(t.Tables)+3)
+	for table, rows := range t.Tables {
+		record[table] = rows
+	}
+	if t.Date != 0 {
+		record["_date"] = t.Date
+	}
+	if t.Comment != "" {
+		record["_comment"] = t.Comment
+	}
+	if t.IsDiff {
+		record["_is_diff"] = true
+	}
+	return json.Marshal(record)
+}
+
+// UnmarshalJSON unmarshals a transaction record
+func (t *Transaction) UnmarshalJSON(b []byte) error {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(b, &record); err != nil {
+		return err
+	}
+	t.Tables = make(map[string]map[string]*ovsdb.Row, len(record))
+	for key, value := range record {
+		var err error
+		switch key {
+		case "_date":
+			var date float64
+			err = json.Unmarshal(value, &date)
+			t.Date = int64(date)
+		case "_comment":
+			err = json.Unmarshal(value, &t.Comment)
+		case "_is_diff":
+			err = json.Unmarshal(value, &t.IsDiff)
+		default:
+			if strings.HasPrefix(key, "_") {
+				// ignore other members that are not tables
+				continue
+			}
+			var rows map[string]*ovsdb.Row
+			err = json.Unmarshal(value, &rows)
+			t.Tables[key] = rows
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// NewTransaction returns the transaction record, in the diff format, of a
+// database update
+func NewTransaction(update database.Update) *Transaction {
+	t := &Transaction{
+		Tables:  make(map[string]map[string]*ovsdb.Row),
+		Date:    time.Now().UnixMilli(),
+		Comment: update.GetComment(),
+		IsDiff:  true,
+	}
+	for _, table := range update.GetUpdatedTables() {
+		rows := make(map[string]*ovsdb.Row)
+		_ = update.ForEachRowUpdate(table, func(uuid string, ru2 ovsdb.RowUpdate2) error {
+			switch {
+			case ru2.Insert != nil:
+				rows[uuid] = recordRow(*ru2.Insert)
+			case ru2.Modify != nil:
+				rows[uuid] = recordRow(*ru2.Modify)
+			case ru2.Delete != nil:
+				rows[uuid] = nil
+			}
+			return nil
+		})
+		if len(rows) > 0 {
+			t.Tables[table] = rows
+		}
+	}
+	return t
+}
+
+// NewSnapshotTransaction returns a transaction record that inserts all the
+// rows of a database. list returns the rows of a table of the database.
+func NewSnapshotTransaction(dbModel model.DatabaseModel, list func(table string) (map[string]model.Model, error)) (*Transaction, error) {
+	t := &Transaction{
+		Tables: make(map[string]map[string]*ovsdb.Row),
+		Date:   time.Now().UnixMilli(),
+	}
+	for table := range dbModel.Types() {
+		models, err := list(table)
+		if err != nil {
+			return nil, err
+		}
+		if len(models) == 0 {
+			continue
+		}
+		rows := make(map[string]*ovsdb.Row, len(models))
+		for uuid, m := range models {
+			row, err := dbModel.NewRow(m)
+			if err != nil {
+				return nil, err
+			}
+			rows[uuid] = recordRow(row)
+		}
+		t.Tables[table] = rows
+	}
+	return t, nil
+}
+
+// recordRow returns a copy of the row without the columns that are not stored
+// in transaction records
+func recordRow(row ovsdb.Row) *ovsdb.Row {
+	r := make(ovsdb.Row, len(row))
+	for column, value := range row {
+		if column == "_uuid" || column == "_version" {
+			continue
+		}
+		r[column] = value
+	}
+	return &r
+}
+
+// ModelUpdates returns the updates that the transaction record makes to the
+// rows of a database. get returns a row of the database, or nil if it does
+// not exist. Tables that are not part of the model are ignored.
+func (t *Transaction) ModelUpdates(dbModel model.DatabaseModel, get func(table, uuid string) (model.Model, error)) (updates.ModelUpdates, error) {
+	mu := updates.ModelUpdates{}
+	types := dbModel.Types()
+	for table, rows := range t.Tables {
+		if _, ok := types[table]; !ok {
+			continue
+		}
+		for uuid, row := range rows {
+			current, err := get(table, uuid)
+			if err != nil {
+				return mu, err
+			}
+			switch {
+			case row == nil && current == nil:
+				return mu, fmt.Errorf("row %s of table %s deleted but it does not exist", uuid, table)
+			case row == nil:
+				err = mu.AddOperation(dbModel, table, uuid, current, &ovsdb.Operation{Op: ovsdb.OperationDelete, Table: table})
+			case current == nil:
+				err = mu.AddOperation(dbModel, table, uuid, nil, &ovsdb.Operation{Op: ovsdb.OperationInsert, Table: table, Row: *row})
+			case t.IsDiff:
+				var old ovsdb.Row
+				old, err = dbModel.NewRow(current)
+				if err == nil {
+					err = mu.AddRowUpdate2(dbModel, table, uuid, current, ovsdb.RowUpdate2{Modify: row, Old: &old})
+				}
+			default:
+				err = mu.AddOperation(dbModel, table, uuid, current, &ovsdb.Operation{Op: ovsdb.OperationUpdate, Table: table, Row: *row})
+			}
+			if err != nil {
+				return mu, err
+			}
+		}
+	}
+	return mu, nil
+}
@@ -35,14 +35,14 @@ func NewDatabase(models map[string]model.ClientDBModel) dbase.Database {
 	}
 }
 
-func (db *inMemoryDatabase) NewTransaction(dbName string) dbase.Transaction {
+func (db *inMemoryDatabase) NewTransaction(dbName string, opts ...dbase.TransactionOption) dbase.Transaction {
 	db.mutex.Lock()
 	defer db.mutex.Unlock()
 	var model model.DatabaseModel
 	if database, ok := db.databases[dbName]; ok {
 		model = database.DatabaseModel()
 	}
-	transaction := transaction.NewTransaction(model, dbName, db, db.logger)
+	transaction := transaction.NewTransaction(model, dbName, db, db.logger, opts...)
 	return &transaction
 }
 
@@ -148,6 +148,62 @@ func TestWaitOpEquals(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestWaitOpEqualsSetOrder(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db := NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	err = db.CreateDatabase("Open_vSwitch", dbModel.Schema)
+	require.NoError(t, err)
+
+	transaction := db.NewTransaction("Open_vSwitch")
+	operations := []ovsdb.Operation{
+		{
+			Op:       ovsdb.OperationInsert,
+			Table:    "Bridge",
+			UUIDName: "foo",
+			Row:      ovsdb.Row{"name": "foo"},
+		},
+		{
+			Op:       ovsdb.OperationInsert,
+			Table:    "Bridge",
+			UUIDName: "bar",
+			Row:      ovsdb.Row{"name": "bar"},
+		},
+		{
+			Op:    ovsdb.OperationInsert,
+			Table: "Open_vSwitch",
+			Row: ovsdb.Row{"bridges": ovsdb.OvsSet{GoSet: []interface{}{
+				ovsdb.UUID{GoUUID: "foo"},
+				ovsdb.UUID{GoUUID: "bar"},
+			}}},
+		},
+	}
+	res, updates := transaction.Transact(operations...)
+	_, err = checkOperationResults(res, operations...)
+	require.NoError(t, err)
+	err = db.Commit("Open_vSwitch", uuid.New(), updates)
+	require.NoError(t, err)
+
+	// the elements of a set are compared regardless of their order
+	timeout := 0
+	operation := ovsdb.Operation{
+		Op:      ovsdb.OperationWait,
+		Table:   "Open_vSwitch",
+		Timeout: &timeout,
+		Where:   []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: res[2].UUID.GoUUID})},
+		Columns: []string{"bridges"},
+		Until:   "==",
+		Rows: []ovsdb.Row{{"bridges": ovsdb.OvsSet{GoSet: []interface{}{
+			ovsdb.UUID{GoUUID: res[1].UUID.GoUUID},
+			ovsdb.UUID{GoUUID: res[0].UUID.GoUUID},
+		}}}},
+	}
+	transaction = db.NewTransaction("Open_vSwitch")
+	res, _ = transaction.Transact(operation)
+	_, err = checkOperationResults(res, operation)
+	require.NoError(t, err)
+}
+
 func TestWaitOpNotEquals(t *testing.T) {
 	dbModel, err := GetModel()
 	require.NoError(t, err)
@@ -1043,3 +1099,147 @@ func TestCheckIndexesWithReferentialIntegrity(t *testing.T) {
 		})
 	}
 }
+
+func TestCommitAbortCommentOps(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db := NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	err = db.CreateDatabase("Open_vSwitch", dbModel.Schema)
+	require.NoError(t, err)
+
+	durable := true
+	first := "ovs-vsctl: add-br foo"
+	second := "ovs-vsctl: set bridge foo datapath_type=netdev"
+	bridgeUUID := uuid.NewString()
+	ops := []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: bridgeUUID, Row: ovsdb.Row{"name": "foo"}},
+		{Op: ovsdb.OperationComment, Comment: &first},
+		{Op: ovsdb.OperationComment, Comment: &second},
+		{Op: ovsdb.OperationCommit, Durable: &durable},
+	}
+	transaction := db.NewTransaction("Open_vSwitch")
+	res, updates := transaction.Transact(ops...)
+	_, err = checkOperationResults(res, ops...)
+	require.NoError(t, err)
+	assert.Equal(t, first+"\n"+second, updates.GetComment())
+	err = db.Commit("Open_vSwitch", uuid.New(), updates)
+	require.NoError(t, err)
+
+	// an aborted transaction is not committed
+	ops = []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "bar"}},
+		{Op: ovsdb.OperationAbort},
+		{Op: ovsdb.OperationComment, Comment: &first},
+	}
+	transaction = db.NewTransaction("Open_vSwitch")
+	res, _ = transaction.Transact(ops...)
+	require.Len(t, res, 3)
+	assert.Equal(t, "", res[0].Error)
+	assert.Equal(t, "aborted", res[1].Error)
+	assert.Nil(t, res[2])
+
+	bridges, err := db.List("Open_vSwitch", "Bridge")
+	require.NoError(t, err)
+	assert.Len(t, bridges, 1)
+	assert.Contains(t, bridges, bridgeUUID)
+}
+
+func TestColumnConstraints(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db := NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	err = db.CreateDatabase("Open_vSwitch", dbModel.Schema)
+	require.NoError(t, err)
+
+	fscsUUID := uuid.NewString()
+	bridge := ovsdb.UUID{GoUUID: uuid.NewString()}
+	ops := []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: bridge.GoUUID, Row: ovsdb.Row{"name": "foo"}},
+		{Op: ovsdb.OperationInsert, Table: "Flow_Sample_Collector_Set", UUID: fscsUUID, Row: ovsdb.Row{"id": 1, "bridge": bridge}},
+	}
+	transaction := db.NewTransaction("Open_vSwitch")
+	res, updates := transaction.Transact(ops...)
+	_, err = checkOperationResults(res, ops...)
+	require.NoError(t, err)
+	err = db.Commit("Open_vSwitch", uuid.New(), updates)
+	require.NoError(t, err)
+
+	where := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: fscsUUID})}
+	tests := []struct {
+		name     string
+		op       ovsdb.Operation
+		expected string
+	}{
+		{
+			"insert out of range",
+			ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Flow_Sample_Collector_Set", Row: ovsdb.Row{"id": -1, "bridge": bridge}},
+			"constraint violation",
+		},
+		{
+			"update out of range",
+			ovsdb.Operation{Op: ovsdb.OperationUpdate, Table: "Flow_Sample_Collector_Set", Where: where, Row: ovsdb.Row{"id": 4294967296}},
+			"constraint violation",
+		},
+		{
+			"mutate out of range",
+			ovsdb.Operation{Op: ovsdb.OperationMutate, Table: "Flow_Sample_Collector_Set", Where: where, Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("id", ovsdb.MutateOperationSubtract, 2)}},
+			"constraint violation",
+		},
+		{
+			"mutate division by zero",
+			ovsdb.Operation{Op: ovsdb.OperationMutate, Table: "Flow_Sample_Collector_Set", Where: where, Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("id", ovsdb.MutateOperationModulo, 0)}},
+			"domain error",
+		},
+		{
+			"update immutable",
+			ovsdb.Operation{Op: ovsdb.OperationUpdate, Table: "Bridge", Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, bridge)}, Row: ovsdb.Row{"name": "bar"}},
+			"constraint violation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := db.NewTransaction("Open_vSwitch")
+			res, _ := transaction.Transact(tt.op)
+			require.NotNil(t, res[0])
+			assert.Equal(t, tt.expected, res[0].Error)
+		})
+	}
+}
+
+func TestMaxRows(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db := NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	err = db.CreateDatabase("Open_vSwitch", dbModel.Schema)
+	require.NoError(t, err)
+
+	// Open_vSwitch holds at most one row
+	ovsUUID := uuid.NewString()
+	ops := []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Open_vSwitch", UUID: ovsUUID, Row: ovsdb.Row{}},
+	}
+	transaction := db.NewTransaction("Open_vSwitch")
+	res, updates := transaction.Transact(ops...)
+	_, err = checkOperationResults(res, ops...)
+	require.NoError(t, err)
+	err = db.Commit("Open_vSwitch", uuid.New(), updates)
+	require.NoError(t, err)
+
+	ops = []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Open_vSwitch", Row: ovsdb.Row{}},
+	}
+	transaction = db.NewTransaction("Open_vSwitch")
+	res, _ = transaction.Transact(ops...)
+	require.Len(t, res, 2)
+	assert.Equal(t, "constraint violation", res[1].Error)
+
+	// replacing the row does not exceed the limit
+	ops = []ovsdb.Operation{
+		{Op: ovsdb.OperationDelete, Table: "Open_vSwitch", Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: ovsUUID})}},
+		{Op: ovsdb.OperationInsert, Table: "Open_vSwitch", Row: ovsdb.Row{}},
+	}
+	transaction = db.NewTransaction("Open_vSwitch")
+	res, _ = transaction.Transact(ops...)
+	_, err = checkOperationResults(res, ops...)
+	require.NoError(t, err)
+}
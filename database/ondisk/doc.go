@@ -0,0 +1,12 @@
+/*
+Package ondisk provides a database implementation that persists its data in
+files in the OVSDB standalone format, the format used by ovsdb-server and
+ovsdb-tool.
+
+Each database is stored in its own file, named after the database, in the
+directory the database is created with. The file is a log that starts with
+the schema of the database, followed by a record for each committed
+transaction. The log is replayed when the database is created, and it can be
+compacted into a single record holding all the rows of the database.
+*/
+package ondisk
@@ -0,0 +1,291 @@
+package ondisk
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/stdr"
+	"github.com/google/uuid"
+	dbase "github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/database/dbfile"
+	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Database is a database that keeps its data in memory and persists it in
+// files in the OVSDB standalone format
+type Database struct {
+	inmemory dbase.Database
+	dir      string
+	models   map[string]model.ClientDBModel
+	files    map[string]*databaseFile
+	logger   *logr.Logger
+	mutex    sync.Mutex
+}
+
+// databaseFile is the file a database is persisted in
+type databaseFile struct {
+	path   string
+	file   *os.File
+	schema ovsdb.DatabaseSchema
+	model  model.DatabaseModel
+	// offset is the offset of the end of the last complete record
+	offset int64
+}
+
+// NewDatabase returns a database that persists the databases created with
+// CreateDatabase in files in the provided directory
+func NewDatabase(dir string, models map[string]model.ClientDBModel) *Database {
+	logger := stdr.NewWithOptions(log.New(os.Stderr, "", log.LstdFlags), stdr.Options{LogCaller: stdr.All}).WithName("database")
+	return &Database{
+		inmemory: inmemory.NewDatabase(models),
+		dir:      dir,
+		models:   models,
+		files:    make(map[string]*databaseFile),
+		logger:   &logger,
+	}
+}
+
+// Path returns the path of the file the provided database is persisted in
+func (db *Database) Path(name string) string {
+	return filepath.Join(db.dir, name+".db")
+}
+
+// CreateDatabase creates a database with the provided schema. If the file of
+// the database already exists, the transactions recorded in it are replayed,
+// otherwise the file is created.
+func (db *Database) CreateDatabase(name string, schema ovsdb.DatabaseSchema) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if _, ok := db.files[name]; ok {
+		return fmt.Errorf("database %s already exists", name)
+	}
+	mo, ok := db.models[schema.Name]
+	if !ok {
+		return fmt.Errorf("no db model provided for schema with name %s", name)
+	}
+	dbModel, errs := model.NewDatabaseModel(schema, mo)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to create DatabaseModel: %#+v", errs)
+	}
+	if err := db.inmemory.CreateDatabase(name, schema); err != nil {
+		return err
+	}
+
+	f := &databaseFile{
+		path:   db.Path(name),
+		schema: schema,
+		model:  dbModel,
+	}
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if info.Size() == 0 {
+		err = db.write(f, func(w *dbfile.Writer) (int, error) {
+			return w.WriteSchema(schema)
+		})
+	} else {
+		err = db.replay(name, f)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+	db.files[name] = f
+	return nil
+}
+
+// replay applies the transactions recorded in the file of a database.
+// An incomplete record at the end of the file is discarded.
+func (db *Database) replay(name string, f *databaseFile) error {
+	reader := dbfile.NewReader(f.file)
+	schema, err := reader.ReadSchema()
+	if err != nil {
+		return fmt.Errorf("failed to read schema of %s: %w", f.path, err)
+	}
+	if schema.Name != f.schema.Name {
+		return fmt.Errorf("file %s holds database %s, expected %s", f.path, schema.Name, f.schema.Name)
+	}
+
+	for {
+		t, err := reader.ReadTransaction()
+		if err == io.EOF {
+			break
+		}
+		if err == dbfile.ErrTruncated {
+			db.logger.Info("discarding incomplete record at the end of the file", "path", f.path, "offset", reader.Offset())
+			if err := f.file.Truncate(reader.Offset()); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		if err := dbfile.ApplyTransaction(db.inmemory, name, f.model, t); err != nil {
+			return fmt.Errorf("failed to replay %s: %w", f.path, err)
+		}
+	}
+	f.offset = reader.Offset()
+	return nil
+}
+
+// write appends a record to the file of a database and flushes it to disk.
+// A record that fails to be written is removed from the file.
+func (db *Database) write(f *databaseFile, record func(*dbfile.Writer) (int, error)) error {
+	n, err := record(dbfile.NewWriter(f.file))
+	if err == nil {
+		err = f.file.Sync()
+	}
+	if err != nil {
+		if terr := f.file.Truncate(f.offset); terr != nil {
+			db.logger.Error(terr, "failed to remove incomplete record", "path", f.path)
+		}
+		return err
+	}
+	f.offset += int64(n)
+	return nil
+}
+
+// Compact rewrites the file of a database with a single record holding all
+// its rows
+func (db *Database) Compact(name string) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	f, ok := db.files[name]
+	if !ok {
+		return fmt.Errorf("db does not exist")
+	}
+
+	list := func(table string) (map[string]model.Model, error) {
+		return db.inmemory.List(name, table)
+	}
+	t, err := dbfile.NewSnapshotTransaction(f.model, list)
+	if err != nil {
+		return err
+	}
+	t.Comment = "compacting database online"
+
+	tmp := f.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	compacted := &databaseFile{
+		path:   f.path,
+		file:   file,
+		schema: f.schema,
+		model:  f.model,
+	}
+	err = db.write(compacted, func(w *dbfile.Writer) (int, error) {
+		return w.WriteSchema(f.schema)
+	})
+	if err == nil {
+		err = db.write(compacted, func(w *dbfile.Writer) (int, error) {
+			return w.WriteTransaction(t)
+		})
+	}
+	if err == nil {
+		err = os.Rename(tmp, f.path)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.file.Close()
+	db.files[name] = compacted
+	return nil
+}
+
+// Close closes the files of the databases
+func (db *Database) Close() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	var err error
+	for name, f := range db.files {
+		if cerr := f.file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(db.files, name)
+	}
+	return err
+}
+
+// NewTransaction returns a new transaction on the provided database
+func (db *Database) NewTransaction(name string, opts ...dbase.TransactionOption) dbase.Transaction {
+	return db.inmemory.NewTransaction(name, opts...)
+}
+
+// Exists returns whether the provided database exists
+func (db *Database) Exists(name string) bool {
+	return db.inmemory.Exists(name)
+}
+
+// Commit records the update in the file of the database before applying it.
+// The record is removed if the update can't be applied.
+func (db *Database) Commit(name string, id uuid.UUID, update dbase.Update) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	f, ok := db.files[name]
+	if !ok {
+		return fmt.Errorf("db does not exist")
+	}
+	offset := f.offset
+	t := dbfile.NewTransaction(update)
+	if len(t.Tables) > 0 {
+		err := db.write(f, func(w *dbfile.Writer) (int, error) {
+			return w.WriteTransaction(t)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := db.inmemory.Commit(name, id, update); err != nil {
+		if f.offset != offset {
+			if terr := f.file.Truncate(offset); terr != nil {
+				db.logger.Error(terr, "failed to remove unapplied record", "path", f.path)
+			} else {
+				f.offset = offset
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// CheckIndexes checks that the model does not violate the indexes of the
+// table
+func (db *Database) CheckIndexes(name string, table string, m model.Model) error {
+	return db.inmemory.CheckIndexes(name, table, m)
+}
+
+// List returns the rows of a table that match the conditions
+func (db *Database) List(name, table string, conditions ...ovsdb.Condition) (map[string]model.Model, error) {
+	return db.inmemory.List(name, table, conditions...)
+}
+
+// Get returns a row of a table
+func (db *Database) Get(name, table string, uuid string) (model.Model, error) {
+	return db.inmemory.Get(name, table, uuid)
+}
+
+// GetReferences returns the references to a row
+func (db *Database) GetReferences(name, table, row string) (dbase.References, error) {
+	return db.inmemory.GetReferences(name, table, row)
+}
+
+var _ dbase.Database = &Database{}
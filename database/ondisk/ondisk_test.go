@@ -0,0 +1,237 @@
+package ondisk
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/database/dbfile"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	. "github.com/ovn-org/libovsdb/test"
+)
+
+func newTestDatabase(t *testing.T, dir string) *Database {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	db := NewDatabase(dir, map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	err = db.CreateDatabase("Open_vSwitch", dbModel.Schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func transact(t *testing.T, db *Database, operations ...ovsdb.Operation) {
+	transaction := db.NewTransaction("Open_vSwitch")
+	results, update := transaction.Transact(operations...)
+	r := make([]ovsdb.OperationResult, len(results))
+	for i := range results {
+		r[i] = *results[i]
+	}
+	_, err := ovsdb.CheckOperationResults(r, operations)
+	require.NoError(t, err)
+	err = db.Commit("Open_vSwitch", uuid.New(), update)
+	require.NoError(t, err)
+}
+
+func bridges(t *testing.T, db *Database) map[string]*BridgeType {
+	models, err := db.List("Open_vSwitch", "Bridge")
+	require.NoError(t, err)
+	bridges := make(map[string]*BridgeType, len(models))
+	for uuid, m := range models {
+		bridges[uuid] = m.(*BridgeType)
+	}
+	return bridges
+}
+
+func transactions(t *testing.T, path string) []*dbfile.Transaction {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	reader := dbfile.NewReader(bytes.NewReader(data))
+	_, err = reader.ReadSchema()
+	require.NoError(t, err)
+	var transactions []*dbfile.Transaction
+	for {
+		transaction, err := reader.ReadTransaction()
+		if err != nil {
+			break
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions
+}
+
+func populate(t *testing.T, db *Database) (string, string) {
+	fooUUID := uuid.NewString()
+	barUUID := uuid.NewString()
+	externalIDs, err := ovsdb.NewOvsMap(map[string]string{"foo": "bar", "baz": "quux"})
+	require.NoError(t, err)
+	transact(t, db,
+		ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: fooUUID, Row: ovsdb.Row{"name": "foo", "external_ids": externalIDs}},
+		ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: barUUID, Row: ovsdb.Row{"name": "bar"}},
+	)
+	mutation, err := ovsdb.NewOvsSet([]string{"baz"})
+	require.NoError(t, err)
+	transact(t, db,
+		ovsdb.Operation{
+			Op:        ovsdb.OperationMutate,
+			Table:     "Bridge",
+			Where:     []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: fooUUID})},
+			Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("external_ids", ovsdb.MutateOperationDelete, mutation)},
+		},
+		ovsdb.Operation{
+			Op:    ovsdb.OperationUpdate,
+			Table: "Bridge",
+			Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: fooUUID})},
+			Row:   ovsdb.Row{"datapath_type": "netdev"},
+		},
+	)
+	transact(t, db, ovsdb.Operation{
+		Op:    ovsdb.OperationDelete,
+		Table: "Bridge",
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: barUUID})},
+	})
+	return fooUUID, barUUID
+}
+
+func TestDatabaseReplay(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDatabase(t, dir)
+	fooUUID, _ := populate(t, db)
+	expected := map[string]*BridgeType{
+		fooUUID: {
+			UUID:         fooUUID,
+			Name:         "foo",
+			DatapathType: "netdev",
+			ExternalIds:  map[string]string{"foo": "bar"},
+		},
+	}
+	assert.Equal(t, expected, bridges(t, db))
+	require.NoError(t, db.Close())
+
+	// a record for each transaction
+	assert.Len(t, transactions(t, db.Path("Open_vSwitch")), 3)
+
+	db = newTestDatabase(t, dir)
+	assert.Equal(t, expected, bridges(t, db))
+
+	// the replayed database keeps recording transactions, along with their
+	// comments
+	comment := "ovs-vsctl: add-br baz"
+	transact(t, db,
+		ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "baz"}},
+		ovsdb.Operation{Op: ovsdb.OperationComment, Comment: &comment},
+	)
+	require.NoError(t, db.Close())
+	recorded := transactions(t, db.Path("Open_vSwitch"))
+	require.Len(t, recorded, 4)
+	assert.Equal(t, comment, recorded[3].Comment)
+	db = newTestDatabase(t, dir)
+	assert.Len(t, bridges(t, db), 2)
+}
+
+func TestDatabaseCompact(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDatabase(t, dir)
+	populate(t, db)
+	expected := bridges(t, db)
+
+	err := db.Compact("Open_vSwitch")
+	require.NoError(t, err)
+	assert.Len(t, transactions(t, db.Path("Open_vSwitch")), 1)
+
+	transact(t, db, ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: uuid.NewString(), Row: ovsdb.Row{"name": "baz"}})
+	assert.Len(t, transactions(t, db.Path("Open_vSwitch")), 2)
+	expected = bridges(t, db)
+	require.NoError(t, db.Close())
+
+	db = newTestDatabase(t, dir)
+	assert.Equal(t, expected, bridges(t, db))
+
+	err = db.Compact("Unknown")
+	assert.Error(t, err)
+}
+
+func TestDatabaseTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDatabase(t, dir)
+	populate(t, db)
+	expected := bridges(t, db)
+	require.NoError(t, db.Close())
+
+	// a crash while writing a record leaves it incomplete
+	file, err := os.OpenFile(db.Path("Open_vSwitch"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString("OVSDB JSON 100 0123456789abcdef0123456789abcdef01234567\n{\"Bridge\":")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	db = newTestDatabase(t, dir)
+	assert.Equal(t, expected, bridges(t, db))
+	transact(t, db, ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "baz"}})
+	require.NoError(t, db.Close())
+
+	db = newTestDatabase(t, dir)
+	assert.Len(t, bridges(t, db), 2)
+}
+
+func TestDatabaseCommitFailure(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDatabase(t, dir)
+	populate(t, db)
+	expected := bridges(t, db)
+
+	// an update that can't be applied, as it inserts a row that exists
+	transaction := db.NewTransaction("Open_vSwitch")
+	_, update := transaction.Transact(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "baz"}})
+	err := db.Commit("Open_vSwitch", uuid.New(), update)
+	require.NoError(t, err)
+	err = db.Commit("Open_vSwitch", uuid.New(), update)
+	require.Error(t, err)
+	transact(t, db, ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "qux"}})
+	require.NoError(t, db.Close())
+
+	// the record of the update is removed
+	assert.Len(t, transactions(t, db.Path("Open_vSwitch")), 5)
+	db = newTestDatabase(t, dir)
+	assert.Len(t, bridges(t, db), len(expected)+2)
+}
+
+func TestDatabaseReplayOvsdbServerFile(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	fooUUID := uuid.NewString()
+	barUUID := uuid.NewString()
+
+	// records as written by ovsdb-server, both in the original format and
+	// in the diff format
+	schema, err := json.Marshal(dbModel.Schema)
+	require.NoError(t, err)
+	file := DatabaseFileRecord(string(schema))
+	for _, record := range []string{
+		`{"Bridge":{"` + fooUUID + `":{"name":"foo","external_ids":["map",[["foo","bar"],["baz","quux"]]]},"` + barUUID + `":{"name":"bar"}},"_date":1700000000000,"_comment":"ovs-vsctl: add-br foo"}`,
+		`{"Bridge":{"` + fooUUID + `":{"datapath_type":"netdev","external_ids":["map",[["foo","bar"]]]}},"_date":1700000000001}`,
+		`{"Bridge":{"` + fooUUID + `":{"external_ids":["map",[["foo","bar"],["qux","quux"]]]},"` + barUUID + `":null},"_date":1700000000002,"_is_diff":true}`,
+	} {
+		file += DatabaseFileRecord(record)
+	}
+	err = os.WriteFile(dir+"/Open_vSwitch.db", []byte(file), 0o640)
+	require.NoError(t, err)
+
+	db := newTestDatabase(t, dir)
+	assert.Equal(t, map[string]*BridgeType{
+		fooUUID: {
+			UUID:         fooUUID,
+			Name:         "foo",
+			DatapathType: "netdev",
+			ExternalIds:  map[string]string{"qux": "quux"},
+		},
+	}, bridges(t, db))
+}
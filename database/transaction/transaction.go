@@ -1,8 +1,9 @@
 package transaction
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -21,21 +22,30 @@ type Transaction struct {
 	Model       model.DatabaseModel
 	DbName      string
 	Database    database.Database
+	Options     database.TransactionOptions
 	logger      *logr.Logger
+	// comments holds the comments provided with comment operations
+	comments []string
 }
 
-func NewTransaction(model model.DatabaseModel, dbName string, database database.Database, logger *logr.Logger) Transaction {
+func NewTransaction(model model.DatabaseModel, dbName string, db database.Database, logger *logr.Logger, opts ...database.TransactionOption) Transaction {
 	if logger != nil {
 		l := logger.WithName("transaction")
 		logger = &l
 	}
 
+	var options database.TransactionOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return Transaction{
 		ID:          uuid.New(),
 		DeletedRows: make(map[string]struct{}),
 		Model:       model,
 		DbName:      dbName,
-		Database:    database,
+		Database:    db,
+		Options:     options,
 		logger:      logger,
 	}
 }
@@ -90,12 +100,15 @@ func (t *Transaction) Transact(operations ...ovsdb.Operation) ([]*ovsdb.Operatio
 		case ovsdb.OperationWait:
 			r = t.Wait(op.Table, op.Timeout, op.Where, op.Columns, op.Until, op.Rows)
 		case ovsdb.OperationCommit:
-			durable := op.Durable
-			r = t.Commit(*durable)
+			r = t.Commit(op.Durable != nil && *op.Durable)
 		case ovsdb.OperationAbort:
 			r = t.Abort()
 		case ovsdb.OperationComment:
-			r = t.Comment(*op.Comment)
+			var comment string
+			if op.Comment != nil {
+				comment = *op.Comment
+			}
+			r = t.Comment(comment)
 		case ovsdb.OperationAssert:
 			r = t.Assert(*op.Lock)
 		default:
@@ -129,7 +142,7 @@ func (t *Transaction) Transact(operations ...ovsdb.Operation) ([]*ovsdb.Operatio
 
 	// if there is no updates, no need to do any further validation
 	if len(update.GetUpdatedTables()) == 0 {
-		return results, updates.NewDatabaseUpdate(update, nil)
+		return results, updates.NewDatabaseUpdate(update, nil).WithComment(t.comment())
 	}
 
 	// check & update references
@@ -149,6 +162,13 @@ func (t *Transaction) Transact(operations ...ovsdb.Operation) ([]*ovsdb.Operatio
 		return results, updates.NewDatabaseUpdate(update, refs)
 	}
 
+	// check the maximum number of rows of the tables
+	if err := t.checkMaxRows(update); err != nil {
+		r := ovsdb.ResultFromError(err)
+		results = append(results, &r)
+		return results, updates.NewDatabaseUpdate(update, refs)
+	}
+
 	// check index constraints
 	if err := t.checkIndexes(); err != nil {
 		if indexExists, ok := err.(*cache.ErrIndexExists); ok {
@@ -163,7 +183,7 @@ func (t *Transaction) Transact(operations ...ovsdb.Operation) ([]*ovsdb.Operatio
 		return results, updates.NewDatabaseUpdate(update, refs)
 	}
 
-	return results, updates.NewDatabaseUpdate(update, refs)
+	return results, updates.NewDatabaseUpdate(update, refs).WithComment(t.comment())
 }
 
 func (t *Transaction) applyReferenceUpdates(update updates.ModelUpdates) error {
@@ -245,6 +265,40 @@ func (t *Transaction) rowsFromTransactionCacheAndDatabase(table string, where []
 	return rows, nil
 }
 
+// checkMaxRows checks that the updated tables do not hold more rows than the
+// maximum allowed by their schema
+func (t *Transaction) checkMaxRows(update updates.ModelUpdates) error {
+	for _, table := range update.GetUpdatedTables() {
+		maxRows := t.Model.Schema.Table(table).MaxRows
+		if maxRows == 0 {
+			continue
+		}
+		var delta int
+		_ = update.ForEachModelUpdate(table, func(uuid string, old, new model.Model) error {
+			switch {
+			case old == nil && new != nil:
+				delta++
+			case old != nil && new == nil:
+				delta--
+			}
+			return nil
+		})
+		if delta <= 0 {
+			continue
+		}
+		rows, err := t.Database.List(t.DbName, table)
+		if err != nil {
+			return err
+		}
+		if len(rows)+delta > maxRows {
+			return ovsdb.NewConstraintViolation(fmt.Sprintf(
+				"transaction causes %q table to contain %d rows, greater than the schema-defined limit of %d row(s)",
+				table, len(rows)+delta, maxRows))
+		}
+	}
+	return nil
+}
+
 // checkIndexes checks that there are no index conflicts:
 // - no duplicate indexes among any two rows operated with in the transaction
 // - no duplicate indexes of any transaction row with any database row
@@ -383,8 +437,19 @@ func (t *Transaction) Delete(op *ovsdb.Operation) (ovsdb.OperationResult, *updat
 	return ovsdb.OperationResult{Count: len(rows)}, &update
 }
 
+// errWaitBlocked is the error of a wait operation that reported that it
+// would block the transaction, see database.TransactionOptions.WaitBlocked
+var errWaitBlocked = errors.New("wait blocked")
+
 func (t *Transaction) Wait(table string, timeout *int, where []ovsdb.Condition, columns []string, until string, rows []ovsdb.Row) ovsdb.OperationResult {
-	start := time.Now()
+	start := t.Options.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	var deadline time.Time
+	if timeout != nil {
+		deadline = start.Add(time.Duration(*timeout) * time.Millisecond)
+	}
 
 	if until != "!=" && until != "==" {
 		return ovsdb.ResultFromError(&ovsdb.NotSupported{})
@@ -432,17 +497,16 @@ Loop:
 						return ovsdb.ResultFromError(err)
 					}
 
-					// check to see if field value is default for given rows
-					// if it is default (not provided) we shouldn't try to compare
-					// for equality
-					if ovsdb.IsDefaultValue(columnSchema, x) {
+					// columns not provided in the given rows are not
+					// compared
+					if _, ok := r[column]; !ok {
 						continue
 					}
 					y, err := info.FieldByColumn(column)
 					if err != nil {
 						return ovsdb.ResultFromError(err)
 					}
-					if !reflect.DeepEqual(x, y) {
+					if !ovsdb.EqualNativeValues(columnSchema, x, y) {
 						foundMatch = false
 					}
 				}
@@ -464,33 +528,67 @@ Loop:
 			return ovsdb.OperationResult{}
 		}
 
-		if timeout != nil {
-			// TODO(trozet): this really shouldn't just break and loop on a time interval
-			// Really this client handler should pause, wait for another handler to update the DB
-			// and then try again. However the server is single threaded for now and not capable of
-			// doing something like that.
-			if time.Since(start) > time.Duration(*timeout)*time.Millisecond {
-				break Loop
-			}
+		if timeout != nil && !time.Now().Before(deadline) {
+			break Loop
+		}
+		// the issuer processes the transaction again once the table
+		// changes, rather than the database being polled
+		if t.Options.WaitBlocked != nil {
+			t.Options.WaitBlocked(table, deadline)
+			return ovsdb.ResultFromError(errWaitBlocked)
+		}
+		if err := t.sleep(200 * time.Millisecond); err != nil {
+			return ovsdb.ResultFromError(err)
 		}
-		time.Sleep(200 * time.Millisecond)
 	}
 
 	return ovsdb.ResultFromError(&ovsdb.TimedOut{})
 }
 
+// sleep waits for the provided duration, or fails with a "canceled" error if
+// the transaction is canceled in the meantime
+func (t *Transaction) sleep(d time.Duration) error {
+	if t.Options.Context == nil {
+		time.Sleep(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-t.Options.Context.Done():
+		return &ovsdb.Canceled{}
+	}
+}
+
+// Commit succeeds regardless of durable: it is up to the database the
+// transaction is committed to whether updates are persisted before the
+// transaction completes
 func (t *Transaction) Commit(durable bool) ovsdb.OperationResult {
-	return ovsdb.ResultFromError(&ovsdb.NotSupported{})
+	return ovsdb.OperationResult{}
 }
 
+// Abort fails the transaction with an "aborted" error, so that none of its
+// updates are committed
 func (t *Transaction) Abort() ovsdb.OperationResult {
-	return ovsdb.ResultFromError(&ovsdb.NotSupported{})
+	return ovsdb.ResultFromError(&ovsdb.Aborted{})
 }
 
+// Comment records a comment to be committed along with the updates of the
+// transaction
 func (t *Transaction) Comment(comment string) ovsdb.OperationResult {
-	return ovsdb.ResultFromError(&ovsdb.NotSupported{})
+	t.comments = append(t.comments, comment)
+	return ovsdb.OperationResult{}
+}
+
+func (t *Transaction) comment() string {
+	return strings.Join(t.comments, "\n")
 }
 
 func (t *Transaction) Assert(lock string) ovsdb.OperationResult {
-	return ovsdb.ResultFromError(&ovsdb.NotSupported{})
+	if t.Options.LockOwner == nil || !t.Options.LockOwner(lock) {
+		return ovsdb.ResultFromError(&ovsdb.NotOwner{})
+	}
+	return ovsdb.OperationResult{}
 }
@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/database"
 	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/database/ondisk"
 	"github.com/ovn-org/libovsdb/example/vswitchd"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
@@ -24,6 +26,7 @@ var (
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to this file")
 	memprofile = flag.String("memoryprofile", "", "write memory profile to this file")
 	port       = flag.Int("port", 56640, "tcp port to listen on")
+	dbDir      = flag.String("db-dir", "", "persist the database in this directory instead of in memory")
 )
 
 func main() {
@@ -58,9 +61,17 @@ func main() {
 		log.Fatal(err)
 	}
 
-	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{
+	models := map[string]model.ClientDBModel{
 		schema.Name: clientDBModel,
-	})
+	}
+	var ovsDB database.Database
+	if *dbDir != "" {
+		db := ondisk.NewDatabase(*dbDir, models)
+		defer db.Close()
+		ovsDB = db
+	} else {
+		ovsDB = inmemory.NewDatabase(models)
+	}
 
 	dbModel, errs := model.NewDatabaseModel(schema, clientDBModel)
 	if len(errs) > 0 {
@@ -91,20 +102,27 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	ovsRow := &vswitchd.OpenvSwitch{
-		UUID: "ovs",
-	}
-	ovsOps, err := c.Create(ovsRow)
+	// a persisted database already has its root row
+	rows, err := ovsDB.List(schema.Name, vswitchd.OpenvSwitchTable)
 	if err != nil {
 		log.Fatal(err)
 	}
-	reply, err := c.Transact(context.Background(), ovsOps...)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = ovsdb.CheckOperationResults(reply, ovsOps)
-	if err != nil {
-		log.Fatal(err)
+	if len(rows) == 0 {
+		ovsRow := &vswitchd.OpenvSwitch{
+			UUID: "ovs",
+		}
+		ovsOps, err := c.Create(ovsRow)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reply, err := c.Transact(context.Background(), ovsOps...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, err = ovsdb.CheckOperationResults(reply, ovsOps)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 	c.Close()
 	log.Printf("listening on tcp::%d", *port)
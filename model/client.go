@@ -14,8 +14,22 @@ type ColumnKey struct {
 	Key    interface{}
 }
 
+// ClientIndexType defines the type of a client index
+type ClientIndexType uint
+
+const (
+	// HashIndexType indexes the rows by the values of a set of columns. It
+	// is used to look up rows by equality. It is the default.
+	HashIndexType ClientIndexType = iota
+	// OrderedIndexType additionally keeps the rows sorted by the value of a
+	// single column of integer, real or string type, optional or not. It is
+	// also used for range conditions and to list the rows in order.
+	OrderedIndexType
+)
+
 // ClientIndex defines a client index by a set of columns
 type ClientIndex struct {
+	Type    ClientIndexType
 	Columns []ColumnKey
 }
 
@@ -58,7 +72,7 @@ func (db ClientDBModel) Indexes(table string) []ClientIndex {
 // as uniqueness is not enforced. They are defined per table and multiple
 // indexes can be defined for a table. Each index consists of a set of columns.
 // If the column is a map, specific keys of that map can be addressed for the
-// index.
+// index. Ordered indexes consist of a single column, without key.
 func (db *ClientDBModel) SetIndexes(indexes map[string][]ClientIndex) {
 	db.indexes = copyIndexes(indexes)
 }
@@ -99,6 +113,12 @@ func (db ClientDBModel) validate(schema ovsdb.DatabaseSchema) []error {
 			continue
 		}
 		for _, indexSet := range indexSets {
+			if indexSet.Type == OrderedIndexType {
+				if err := validateOrderedIndex(info, indexSet); err != nil {
+					errors = append(errors, fmt.Errorf("database model contains an invalid ordered client index for table %s: %w", tableName, err))
+					continue
+				}
+			}
 			for _, indexColumn := range indexSet.Columns {
 				f, err := info.FieldByColumn(indexColumn.Column)
 				if err != nil {
@@ -124,6 +144,28 @@ func (db ClientDBModel) validate(schema ovsdb.DatabaseSchema) []error {
 	return errors
 }
 
+// validateOrderedIndex checks that an ordered index consists of a single
+// column with a type that can be ordered
+func validateOrderedIndex(info *mapper.Info, index ClientIndex) error {
+	if len(index.Columns) != 1 || index.Columns[0].Key != nil {
+		return fmt.Errorf("an ordered index must consist of a single column without key")
+	}
+	f, err := info.FieldByColumn(index.Columns[0].Column)
+	if err != nil {
+		// reported along with the other indexes
+		return nil
+	}
+	t := reflect.TypeOf(f)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Float64, reflect.String:
+		return nil
+	}
+	return fmt.Errorf("column %s of type %s cannot be ordered", index.Columns[0].Column, t)
+}
+
 // NewClientDBModel constructs a ClientDBModel based on a database name and dictionary of models indexed by table name
 func NewClientDBModel(name string, models map[string]Model) (ClientDBModel, error) {
 	types := make(map[string]reflect.Type, len(models))
@@ -161,6 +203,7 @@ func copyIndexes(src map[string][]ClientIndex) map[string][]ClientIndex {
 		dst[table] = make([]ClientIndex, 0, len(indexSets))
 		for _, indexSet := range indexSets {
 			indexSetCopy := ClientIndex{
+				Type:    indexSet.Type,
 				Columns: make([]ColumnKey, len(indexSet.Columns)),
 			}
 			copy(indexSetCopy.Columns, indexSet.Columns)
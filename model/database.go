@@ -116,3 +116,13 @@ func (db DatabaseModel) NewModelInfo(obj interface{}) (*mapper.Info, error) {
 		Metadata: meta,
 	}, nil
 }
+
+// NewRow returns the row of a model that is part of the DatabaseModel. If
+// fields are provided, only those are included in the row.
+func (db DatabaseModel) NewRow(obj interface{}, fields ...interface{}) (ovsdb.Row, error) {
+	info, err := db.NewModelInfo(obj)
+	if err != nil {
+		return nil, err
+	}
+	return db.Mapper.NewRow(info, fields...)
+}
@@ -97,6 +97,36 @@ type Mutation struct {
 	Value interface{}
 }
 
+// NewCondition returns a Condition on the field of a model pointed to by
+// field. The value is checked at compile time to be of the type of the field.
+func NewCondition[V any](field *V, function ovsdb.ConditionFunction, value V) Condition {
+	return Condition{
+		Field:    field,
+		Function: function,
+		Value:    value,
+	}
+}
+
+// NewMutation returns a Mutation of the field of a model pointed to by field.
+// The value is checked at compile time to be of the type of the field.
+func NewMutation[V any](field *V, mutator ovsdb.Mutator, value V) Mutation {
+	return Mutation{
+		Field:   field,
+		Mutator: mutator,
+		Value:   value,
+	}
+}
+
+// NewMapKeysDeletion returns a Mutation deleting the provided keys, whatever
+// their value, from the map field of a model pointed to by field
+func NewMapKeysDeletion[K comparable, V any](field *map[K]V, keys ...K) Mutation {
+	return Mutation{
+		Field:   field,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   keys,
+	}
+}
+
 // CreateModel creates a new Model instance based on an OVSDB Row information
 func CreateModel(dbModel DatabaseModel, tableName string, row *ovsdb.Row, uuid string) (Model, error) {
 	if !dbModel.Valid() {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type modelA struct {
@@ -336,6 +337,76 @@ func TestValidate(t *testing.T) {
 
 }
 
+func TestValidateOrderedIndexes(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+	    "name": "TestDB",
+	    "tables": {
+	      "TestTable": {
+	        "columns": {
+	          "aString": { "type": "string" },
+	          "aInt": { "type": { "key": "integer", "min": 0, "max": 1 } },
+	          "aSet": { "type": { "key": "string", "max": "unlimited", "min": 0 } },
+	          "aMap": { "type": { "key": "string", "max": "unlimited", "min": 0, "value": "string" } }
+	        }
+	      }
+	    }
+	}`), &schema)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		index ClientIndex
+		err   bool
+	}{
+		{
+			name:  "string column",
+			index: ClientIndex{Type: OrderedIndexType, Columns: []ColumnKey{{Column: "aString"}}},
+		},
+		{
+			name:  "optional integer column",
+			index: ClientIndex{Type: OrderedIndexType, Columns: []ColumnKey{{Column: "aInt"}}},
+		},
+		{
+			name:  "set column",
+			index: ClientIndex{Type: OrderedIndexType, Columns: []ColumnKey{{Column: "aSet"}}},
+			err:   true,
+		},
+		{
+			name:  "map key",
+			index: ClientIndex{Type: OrderedIndexType, Columns: []ColumnKey{{Column: "aMap", Key: "key"}}},
+			err:   true,
+		},
+		{
+			name:  "multiple columns",
+			index: ClientIndex{Type: OrderedIndexType, Columns: []ColumnKey{{Column: "aString"}, {Column: "aInt"}}},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := NewClientDBModel("TestDB", map[string]Model{
+				"TestTable": &struct {
+					UUID   string            `ovsdb:"_uuid"`
+					String string            `ovsdb:"aString"`
+					Int    *int              `ovsdb:"aInt"`
+					Set    []string          `ovsdb:"aSet"`
+					Map    map[string]string `ovsdb:"aMap"`
+				}{},
+			})
+			require.NoError(t, err)
+			model.SetIndexes(map[string][]ClientIndex{"TestTable": {tt.index}})
+			assert.Equal(t, OrderedIndexType, model.Indexes("TestTable")[0].Type)
+			errors := model.validate(schema)
+			if tt.err {
+				assert.Len(t, errors, 1)
+			} else {
+				assert.Empty(t, errors)
+			}
+		})
+	}
+}
+
 type modelC struct {
 	modelB
 	NoClone string
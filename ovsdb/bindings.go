@@ -292,6 +292,26 @@ func IsDefaultValue(column *ColumnSchema, nativeElem interface{}) bool {
 	}
 }
 
+// EqualNativeValues returns whether two native values of a column are equal,
+// regardless of the order of the elements of sets and maps, like the columns
+// of rows are compared by RFC 7047
+func EqualNativeValues(column *ColumnSchema, a, b interface{}) bool {
+	if column != nil && IsDefaultValue(column, a) && IsDefaultValue(column, b) {
+		return true
+	}
+	switch reflect.ValueOf(a).Kind() {
+	case reflect.Slice, reflect.Map:
+		includes, err := ConditionIncludes.Evaluate(a, b)
+		if err != nil || !includes {
+			return false
+		}
+		included, err := ConditionIncludes.Evaluate(b, a)
+		return err == nil && included
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
 // ValidateMutationAtomic checks if the mutation is valid for a specific AtomicType
 func validateMutationAtomic(atype string, mutator Mutator, value interface{}) error {
 	nType := NativeTypeFromAtomic(atype)
@@ -304,14 +324,24 @@ func validateMutationAtomic(atype string, mutator Mutator, value interface{}) er
 		return fmt.Errorf("atomictype %s does not support mutation", atype)
 	case TypeReal:
 		switch mutator {
-		case MutateOperationAdd, MutateOperationSubtract, MutateOperationMultiply, MutateOperationDivide:
+		case MutateOperationAdd, MutateOperationSubtract, MutateOperationMultiply:
+			return nil
+		case MutateOperationDivide:
+			if value.(float64) == 0 {
+				return &DomainError{details: "division by zero"}
+			}
 			return nil
 		default:
 			return fmt.Errorf("wrong mutator for real type %s", mutator)
 		}
 	case TypeInteger:
 		switch mutator {
-		case MutateOperationAdd, MutateOperationSubtract, MutateOperationMultiply, MutateOperationDivide, MutateOperationModulo:
+		case MutateOperationAdd, MutateOperationSubtract, MutateOperationMultiply:
+			return nil
+		case MutateOperationDivide, MutateOperationModulo:
+			if value.(int) == 0 {
+				return &DomainError{details: "division by zero"}
+			}
 			return nil
 		default:
 			return fmt.Errorf("wrong mutator for integer type: %s", mutator)
@@ -325,7 +355,7 @@ func validateMutationAtomic(atype string, mutator Mutator, value interface{}) er
 // for a given column based on the rules specified RFC7047
 func ValidateMutation(column *ColumnSchema, mutator Mutator, value interface{}) error {
 	if !column.Mutable() {
-		return fmt.Errorf("column is not mutable")
+		return NewConstraintViolation("column is not mutable")
 	}
 	switch column.Type {
 	case TypeSet:
@@ -377,6 +407,54 @@ func ValidateMutation(column *ColumnSchema, mutator Mutator, value interface{})
 	}
 }
 
+// ValidateValue checks if a native value satisfies the constraints of a
+// column as specified in RFC7047: the number of elements of sets and maps, and
+// the enum, range and length constraints of their keys and values
+func ValidateValue(column *ColumnSchema, nativeValue interface{}) error {
+	if column.TypeObj == nil {
+		return nil
+	}
+	var keys, values []interface{}
+	value := reflect.ValueOf(nativeValue)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			keys = append(keys, value.Elem().Interface())
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			keys = append(keys, value.Index(i).Interface())
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key().Interface())
+			values = append(values, iter.Value().Interface())
+		}
+	default:
+		keys = append(keys, nativeValue)
+	}
+
+	min, max := column.TypeObj.Min(), column.TypeObj.Max()
+	if len(keys) < min || (max != Unlimited && len(keys) > max) {
+		if max == Unlimited {
+			return NewConstraintViolation(fmt.Sprintf("%d elements are present but at least %d are required", len(keys), min))
+		}
+		return NewConstraintViolation(fmt.Sprintf("%d elements are present but between %d and %d are required", len(keys), min, max))
+	}
+	for _, key := range keys {
+		if err := column.TypeObj.Key.validate(key); err != nil {
+			return err
+		}
+	}
+	for _, value := range values {
+		if err := column.TypeObj.Value.validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func ValidateCondition(column *ColumnSchema, function ConditionFunction, nativeValue interface{}) error {
 	if NativeType(column) != reflect.TypeOf(nativeValue) {
 		return NewErrWrongType(fmt.Sprintf("Condition for column %s", column),
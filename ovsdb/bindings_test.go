@@ -810,6 +810,75 @@ func TestIsDefault(t *testing.T) {
 	}
 }
 
+func TestEqualNativeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		column   []byte
+		a        interface{}
+		b        interface{}
+		expected bool
+	}{
+		{
+			name:     "equal strings",
+			column:   []byte(`{"type":"string"}`),
+			a:        "foo",
+			b:        "foo",
+			expected: true,
+		},
+		{
+			name:     "different strings",
+			column:   []byte(`{"type":"string"}`),
+			a:        "foo",
+			b:        "bar",
+			expected: false,
+		},
+		{
+			name:     "sets in a different order",
+			column:   []byte(`{"type":{"key":"uuid","min":0,"max":"unlimited"}}`),
+			a:        []string{aUUID0, aUUID1},
+			b:        []string{aUUID1, aUUID0},
+			expected: true,
+		},
+		{
+			name:     "different sets",
+			column:   []byte(`{"type":{"key":"uuid","min":0,"max":"unlimited"}}`),
+			a:        []string{aUUID0, aUUID1},
+			b:        []string{aUUID0},
+			expected: false,
+		},
+		{
+			name:     "nil and empty sets",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			a:        []string(nil),
+			b:        []string{},
+			expected: true,
+		},
+		{
+			name:     "equal maps",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			a:        map[string]string{"foo": "bar", "baz": "quux"},
+			b:        map[string]string{"baz": "quux", "foo": "bar"},
+			expected: true,
+		},
+		{
+			name:     "different maps",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			a:        map[string]string{"foo": "bar"},
+			b:        map[string]string{"foo": "baz"},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("EqualNativeValues: %s", test.name), func(t *testing.T) {
+			var column ColumnSchema
+			if err := json.Unmarshal(test.column, &column); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expected, EqualNativeValues(&column, test.a, test.b))
+		})
+	}
+}
+
 func TestMutationValidation(t *testing.T) {
 	type Test struct {
 		name     string
@@ -1009,6 +1078,27 @@ func TestMutationValidation(t *testing.T) {
 			value:    map[string]string{"foo": "bar"},
 			valid:    true,
 		},
+		{
+			name:     "integer division by zero",
+			column:   []byte(`{"type":"integer"}`),
+			mutators: []Mutator{MutateOperationDivide, MutateOperationModulo},
+			value:    0,
+			valid:    false,
+		},
+		{
+			name:     "real division by zero",
+			column:   []byte(`{"type":"real"}`),
+			mutators: []Mutator{MutateOperationDivide},
+			value:    0.0,
+			valid:    false,
+		},
+		{
+			name:     "immutable",
+			column:   []byte(`{"type":"integer","mutable":false}`),
+			mutators: []Mutator{MutateOperationAdd},
+			value:    1,
+			valid:    false,
+		},
 		{
 			name: "map delete k set",
 			column: []byte(`{
@@ -1166,3 +1256,143 @@ func TestConditionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestValueValidation(t *testing.T) {
+	type Test struct {
+		name   string
+		column []byte
+		value  interface{}
+		err    error
+	}
+	tests := []Test{
+		{
+			name:   "enum",
+			column: []byte(`{"type":{"key":{"type":"string","enum":["set",["system","netdev"]]}}}`),
+			value:  "netdev",
+		},
+		{
+			name:   "enum not allowed",
+			column: []byte(`{"type":{"key":{"type":"string","enum":["set",["system","netdev"]]}}}`),
+			value:  "dpdk",
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "integer enum",
+			column: []byte(`{"type":{"key":{"type":"integer","enum":["set",[1,2]]}}}`),
+			value:  2,
+		},
+		{
+			name:   "integer in range",
+			column: []byte(`{"type":{"key":{"type":"integer","minInteger":1,"maxInteger":10}}}`),
+			value:  10,
+		},
+		{
+			name:   "integer below range",
+			column: []byte(`{"type":{"key":{"type":"integer","minInteger":1,"maxInteger":10}}}`),
+			value:  0,
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "integer above range",
+			column: []byte(`{"type":{"key":{"type":"integer","minInteger":1,"maxInteger":10}}}`),
+			value:  11,
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "real below range",
+			column: []byte(`{"type":{"key":{"type":"real","minReal":-1.5,"maxReal":1.5}}}`),
+			value:  -2.0,
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "real in range",
+			column: []byte(`{"type":{"key":{"type":"real","minReal":-1.5,"maxReal":1.5}}}`),
+			value:  -1.5,
+		},
+		{
+			name:   "string length",
+			column: []byte(`{"type":{"key":{"type":"string","minLength":1,"maxLength":3}}}`),
+			value:  "fóo",
+		},
+		{
+			name:   "string too short",
+			column: []byte(`{"type":{"key":{"type":"string","minLength":1,"maxLength":3}}}`),
+			value:  "",
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "string too long",
+			column: []byte(`{"type":{"key":{"type":"string","minLength":1,"maxLength":3}}}`),
+			value:  "quux",
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "optional",
+			column: []byte(`{"type":{"key":{"type":"integer","maxInteger":10},"min":0,"max":1}}`),
+			value:  (*int)(nil),
+		},
+		{
+			name:   "optional above range",
+			column: []byte(`{"type":{"key":{"type":"integer","maxInteger":10},"min":0,"max":1}}`),
+			value: func() *int {
+				v := 11
+				return &v
+			}(),
+			err: &ConstraintViolation{},
+		},
+		{
+			name:   "set",
+			column: []byte(`{"type":{"key":"string","min":1,"max":2}}`),
+			value:  []string{"foo", "bar"},
+		},
+		{
+			name:   "set too small",
+			column: []byte(`{"type":{"key":"string","min":1,"max":2}}`),
+			value:  []string{},
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "set too large",
+			column: []byte(`{"type":{"key":"string","min":1,"max":2}}`),
+			value:  []string{"foo", "bar", "baz"},
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "set key out of range",
+			column: []byte(`{"type":{"key":{"type":"integer","maxInteger":10},"min":0,"max":"unlimited"}}`),
+			value:  []int{1, 100},
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "map",
+			column: []byte(`{"type":{"key":"string","value":{"type":"string","enum":["set",["true","false"]]},"min":0,"max":"unlimited"}}`),
+			value:  map[string]string{"foo": "true"},
+		},
+		{
+			name:   "map value not allowed",
+			column: []byte(`{"type":{"key":"string","value":{"type":"string","enum":["set",["true","false"]]},"min":0,"max":"unlimited"}}`),
+			value:  map[string]string{"foo": "yes"},
+			err:    &ConstraintViolation{},
+		},
+		{
+			name:   "map too large",
+			column: []byte(`{"type":{"key":"string","value":"string","min":0,"max":1}}`),
+			value:  map[string]string{"foo": "bar", "baz": "quux"},
+			err:    &ConstraintViolation{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("ValueValidation: %s", test.name), func(t *testing.T) {
+			var column ColumnSchema
+			if err := json.Unmarshal(test.column, &column); err != nil {
+				t.Fatal(err)
+			}
+			err := ValidateValue(&column, test.value)
+			if test.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.IsType(t, test.err, err)
+			}
+		})
+	}
+}
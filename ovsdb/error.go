@@ -14,6 +14,7 @@ const (
 	notSupported                  = "not supported"
 	aborted                       = "aborted"
 	notOwner                      = "not owner"
+	canceled                      = "canceled"
 )
 
 // errorFromResult returns an specific OVSDB error type from
@@ -45,6 +46,8 @@ func errorFromResult(op *Operation, r OperationResult) OperationError {
 		return &Aborted{r.Details, op}
 	case notOwner:
 		return &NotOwner{r.Details, op}
+	case canceled:
+		return &Canceled{r.Details, op}
 	default:
 		return &Error{r.Error, r.Details, op}
 	}
@@ -77,6 +80,8 @@ func ResultFromError(err error) OperationResult {
 		return OperationResult{Error: aborted, Details: e.details}
 	case *NotOwner:
 		return OperationResult{Error: notOwner, Details: e.details}
+	case *Canceled:
+		return OperationResult{Error: canceled, Details: e.details}
 	default:
 		return OperationResult{Error: e.Error()}
 	}
@@ -350,6 +355,27 @@ func (e *NotOwner) Operation() *Operation {
 	return e.operation
 }
 
+// Canceled is the error of a transaction canceled by a cancel request, as
+// described in RFC 7047: 4.1.4
+type Canceled struct {
+	details   string
+	operation *Operation
+}
+
+// Error implements the error interface
+func (e *Canceled) Error() string {
+	msg := canceled
+	if e.details != "" {
+		msg += ": " + e.details
+	}
+	return msg
+}
+
+// Operation implements the OperationError interface
+func (e *Canceled) Operation() *Operation {
+	return e.operation
+}
+
 // Error is a generic OVSDB Error type that implements the
 // OperationError and error interfaces
 type Error struct {
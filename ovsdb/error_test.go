@@ -72,6 +72,11 @@ func TestErrorFromResult(t *testing.T) {
 			args{nil, OperationResult{Error: notOwner}},
 			&NotOwner{},
 		},
+		{
+			canceled,
+			args{nil, OperationResult{Error: canceled}},
+			&Canceled{},
+		},
 		{
 			"generic error",
 			args{nil, OperationResult{Error: "foo"}},
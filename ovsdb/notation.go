@@ -87,6 +87,13 @@ type MonitorRequest struct {
 	Select  *MonitorSelect `json:"select,omitempty"`
 }
 
+// MonitorCondChangeRequest represents a change of the conditions of a
+// monitored table according to ovsdb-server.7
+type MonitorCondChangeRequest struct {
+	Columns []string    `json:"columns,omitempty"`
+	Where   []Condition `json:"where"`
+}
+
 // TransactResponse represents the response to a Transact Operation
 type TransactResponse struct {
 	Result []OperationResult `json:"result"`
@@ -48,6 +48,11 @@ func NewMonitorCondSinceArgs(database string, value interface{}, requests map[st
 	return []interface{}{database, value, requests, lastTransactionID}
 }
 
+// NewMonitorCondChangeArgs creates a new set of arguments for a monitor_cond_change RPC
+func NewMonitorCondChangeArgs(value, newValue interface{}, requests map[string][]MonitorCondChangeRequest) []interface{} {
+	return []interface{}{value, newValue, requests}
+}
+
 // NewMonitorCancelArgs creates a new set of arguments for a monitor_cancel RPC
 func NewMonitorCancelArgs(value interface{}) []interface{} {
 	return []interface{}{value}
@@ -58,6 +63,11 @@ func NewLockArgs(id interface{}) []interface{} {
 	return []interface{}{id}
 }
 
+// LockResult is the result of a lock or steal RPC
+type LockResult struct {
+	Locked bool `json:"locked"`
+}
+
 // NotificationHandler is the interface that must be implemented to receive notifications
 type NotificationHandler interface {
 	// RFC 7047 section 4.1.6 Update Notification
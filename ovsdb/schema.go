@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 // DatabaseSchema is a database schema according to RFC7047
@@ -132,6 +133,8 @@ type TableSchema struct {
 	Columns map[string]*ColumnSchema `json:"columns"`
 	Indexes [][]string               `json:"indexes,omitempty"`
 	IsRoot  bool                     `json:"isRoot,omitempty"`
+	// MaxRows is the maximum number of rows of the table, 0 if unlimited
+	MaxRows int `json:"maxRows,omitempty"`
 }
 
 // Column returns the Column object for a specific column name
@@ -312,6 +315,53 @@ func (b *BaseType) RefType() (RefType, error) {
 	return Strong, nil
 }
 
+// validate checks that a native atomic value satisfies the constraints of the
+// base type: the enum, the range of integers and reals, and the length of
+// strings
+func (b *BaseType) validate(value interface{}) error {
+	if len(b.Enum) > 0 {
+		var allowed bool
+		for _, e := range b.Enum {
+			native, err := OvsToNativeAtomic(b.Type, e)
+			if err == nil && native == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return NewConstraintViolation(fmt.Sprintf("%v is not one of the allowed values %v", value, b.Enum))
+		}
+	}
+	switch v := value.(type) {
+	case int:
+		if b.minInteger != nil && v < *b.minInteger {
+			return NewConstraintViolation(fmt.Sprintf("%d is less than minimum allowed value %d", v, *b.minInteger))
+		}
+		if b.maxInteger != nil && v > *b.maxInteger {
+			return NewConstraintViolation(fmt.Sprintf("%d is greater than maximum allowed value %d", v, *b.maxInteger))
+		}
+	case float64:
+		if b.minReal != nil && v < *b.minReal {
+			return NewConstraintViolation(fmt.Sprintf("%g is less than minimum allowed value %g", v, *b.minReal))
+		}
+		if b.maxReal != nil && v > *b.maxReal {
+			return NewConstraintViolation(fmt.Sprintf("%g is greater than maximum allowed value %g", v, *b.maxReal))
+		}
+	case string:
+		if b.Type != TypeString {
+			break
+		}
+		length := utf8.RuneCountInString(v)
+		if b.minLength != nil && length < *b.minLength {
+			return NewConstraintViolation(fmt.Sprintf("%q length %d is less than minimum allowed length %d", v, length, *b.minLength))
+		}
+		if b.maxLength != nil && length > *b.maxLength {
+			return NewConstraintViolation(fmt.Sprintf("%q length %d is greater than maximum allowed length %d", v, length, *b.maxLength))
+		}
+	}
+	return nil
+}
+
 // UnmarshalJSON unmarshals a json-formatted base type
 func (b *BaseType) UnmarshalJSON(data []byte) error {
 	var s string
@@ -359,7 +409,7 @@ func (b *BaseType) UnmarshalJSON(data []byte) error {
 	b.maxReal = bt.MaxReal
 	b.minInteger = bt.MinInteger
 	b.maxInteger = bt.MaxInteger
-	b.minLength = bt.MaxLength
+	b.minLength = bt.MinLength
 	b.maxLength = bt.MaxLength
 	b.refTable = bt.RefTable
 	b.refType = bt.RefType
@@ -385,7 +435,7 @@ func (b BaseType) MarshalJSON() ([]byte, error) {
 		MaxReal:    b.maxReal,
 		MinInteger: b.minInteger,
 		MaxInteger: b.maxInteger,
-		MinLength:  b.maxLength,
+		MinLength:  b.minLength,
 		MaxLength:  b.maxLength,
 		RefTable:   b.refTable,
 		RefType:    b.refType,
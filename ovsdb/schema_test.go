@@ -369,7 +369,8 @@ func TestTable(t *testing.T) {
 		        "bar": {
 			  "type": "string"
 			}
-		      }
+		      },
+		      "maxRows": 2
 		    }
 		}
 	    }`)
@@ -404,12 +405,25 @@ func TestTable(t *testing.T) {
 		column := table.Column("_uuid")
 		assert.NotNil(t, column)
 	})
+	t.Run("MaxRows", func(t *testing.T) {
+		table := schema.Table("test")
+		assert.NotNil(t, table)
+		assert.Equal(t, 2, table.MaxRows)
+		raw, err := json.Marshal(table)
+		assert.Nil(t, err)
+		var unmarshalled TableSchema
+		err = json.Unmarshal(raw, &unmarshalled)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, unmarshalled.MaxRows)
+	})
 }
 
 func TestBaseTypeMarshalUnmarshalJSON(t *testing.T) {
 	datapath := "Datapath"
 	zero := 0
 	max := 4294967295
+	one := 1
+	sixtyThree := 63
 	strong := "strong"
 	tests := []struct {
 		name         string
@@ -474,6 +488,13 @@ func TestBaseTypeMarshalUnmarshalJSON(t *testing.T) {
 			[]byte(`{"type":"integer","minInteger":0,"maxInteger": 4294967295}`),
 			false,
 		},
+		{
+			"string with min and max length",
+			[]byte(`{"type":"string","minLength":1,"maxLength":63}`),
+			BaseType{Type: TypeString, minLength: &one, maxLength: &sixtyThree},
+			[]byte(`{"type":"string","minLength":1,"maxLength":63}`),
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+)
+
+// cancelableRequest is implemented by the arguments of the requests that a
+// cancel request can abort
+type cancelableRequest interface {
+	// setContext sets the context of the request, and the function to call
+	// once it is handled
+	setContext(ctx context.Context, done func())
+	// params returns the value the parameters of the request are read into
+	params() interface{}
+}
+
+// TransactRequest holds the arguments of a transact request along with the
+// context that is canceled when a cancel request refers to it
+type TransactRequest struct {
+	Args []json.RawMessage
+	ctx  context.Context
+	done func()
+}
+
+func (r *TransactRequest) setContext(ctx context.Context, done func()) {
+	r.ctx = ctx
+	r.done = done
+}
+
+func (r *TransactRequest) params() interface{} {
+	return &r.Args
+}
+
+// Context returns the context of the request
+func (r *TransactRequest) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// Done releases the context of the request once it is handled
+func (r *TransactRequest) Done() {
+	if r.done != nil {
+		r.done()
+	}
+}
+
+// requestKey returns the key identifying a request by its JSON-RPC id, or an
+// empty string for a notification
+func requestKey(id interface{}) string {
+	if raw, ok := id.(json.RawMessage); ok {
+		if len(raw) == 0 {
+			return ""
+		}
+		// the id is compared as a JSON value
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return ""
+		}
+	}
+	if id == nil {
+		return ""
+	}
+	b, err := json.Marshal(id)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// jsonCodec wraps the JSON-RPC codec of rpc2 to process the cancel requests.
+// rpc2 runs the handlers concurrently, so the requests are canceled as they
+// are read, in order, rather than by a handler that could run before the one
+// of the request to cancel. The messages are read from the connection first
+// to learn the JSON-RPC id of the requests, which cancel requests refer to,
+// and then handed over as they are to the codec of rpc2.
+type jsonCodec struct {
+	rpc2.Codec
+	dec *json.Decoder
+	// message holds the message being handed over to the codec of rpc2
+	message bytes.Buffer
+
+	// id and seq are the JSON-RPC id and the sequence number of the request
+	// being read
+	id  json.RawMessage
+	seq uint64
+
+	// cancels holds the functions canceling the requests in progress by
+	// sequence number, and requests their sequence number by the key of
+	// their id
+	mutex    sync.Mutex
+	cancels  map[uint64]context.CancelFunc
+	requests map[string]uint64
+}
+
+// codecConn is the connection of the codec of rpc2, which reads the messages
+// handed over to it
+type codecConn struct {
+	io.ReadWriteCloser
+	message *bytes.Buffer
+}
+
+func (c codecConn) Read(p []byte) (int, error) {
+	return c.message.Read(p)
+}
+
+// newJSONCodec returns a new jsonCodec on conn
+func newJSONCodec(conn io.ReadWriteCloser) rpc2.Codec {
+	c := &jsonCodec{
+		dec:      json.NewDecoder(conn),
+		cancels:  make(map[uint64]context.CancelFunc),
+		requests: make(map[string]uint64),
+	}
+	c.Codec = jsonrpc.NewJSONCodec(codecConn{ReadWriteCloser: conn, message: &c.message})
+	return c
+}
+
+// ReadHeader implements the rpc2.Codec interface
+func (c *jsonCodec) ReadHeader(req *rpc2.Request, resp *rpc2.Response) error {
+	var raw json.RawMessage
+	if err := c.dec.Decode(&raw); err != nil {
+		return err
+	}
+	var header struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	// a malformed message is reported by the codec of rpc2
+	_ = json.Unmarshal(raw, &header)
+	c.message.Reset()
+	c.message.Write(raw)
+	if err := c.Codec.ReadHeader(req, resp); err != nil {
+		return err
+	}
+	c.id = header.ID
+	c.seq = req.Seq
+	if header.Method == "cancel" {
+		var params []interface{}
+		if err := c.Codec.ReadRequestBody(&params); err == nil && len(params) == 1 {
+			c.cancel(requestKey(params[0]))
+		}
+	}
+	return nil
+}
+
+// ReadRequestBody implements the rpc2.Codec interface
+func (c *jsonCodec) ReadRequestBody(x interface{}) error {
+	if r, ok := x.(cancelableRequest); ok {
+		ctx, done := c.track(c.id, c.seq)
+		r.setContext(ctx, done)
+		return c.Codec.ReadRequestBody(r.params())
+	}
+	return c.Codec.ReadRequestBody(x)
+}
+
+// Close implements the rpc2.Codec interface. The requests in progress are
+// canceled.
+func (c *jsonCodec) Close() error {
+	c.mutex.Lock()
+	for _, cancel := range c.cancels {
+		cancel()
+	}
+	c.cancels = map[uint64]context.CancelFunc{}
+	c.requests = map[string]uint64{}
+	c.mutex.Unlock()
+	return c.Codec.Close()
+}
+
+// track returns the context of a request, that is canceled by a cancel
+// request referring to its id, and the function releasing it
+func (c *jsonCodec) track(id json.RawMessage, seq uint64) (context.Context, func()) {
+	key := requestKey(id)
+	if key == "" || seq == 0 {
+		// a notification cannot be referred to
+		return context.Background(), func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cancels[seq] = cancel
+	c.requests[key] = seq
+	return ctx, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		cancel()
+		delete(c.cancels, seq)
+		if c.requests[key] == seq {
+			delete(c.requests, key)
+		}
+	}
+}
+
+// cancel cancels the context of the request in progress with the provided id
+// key, if any
+func (c *jsonCodec) cancel(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if seq, ok := c.requests[key]; ok {
+		c.cancels[seq]()
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/updates"
+)
+
+// defaultHistorySize is the number of committed transactions kept for each
+// database
+const defaultHistorySize = 100
+
+// transaction is a committed transaction of a database
+type transaction struct {
+	id     uuid.UUID
+	update database.Update
+}
+
+// transactionHistory keeps the most recent transactions committed to a
+// database so that a client monitoring it with monitor_cond_since can be sent
+// only the changes it missed since the last transaction it knows about
+type transactionHistory struct {
+	transactions []transaction
+	size         int
+	// committed is the number of transactions ever committed
+	committed int
+}
+
+func newTransactionHistory(size int) *transactionHistory {
+	return &transactionHistory{
+		transactions: make([]transaction, 0, size),
+		size:         size,
+	}
+}
+
+// add records a committed transaction, forgetting the oldest one if the
+// history is full
+func (h *transactionHistory) add(id uuid.UUID, update database.Update) {
+	h.committed++
+	if h.size <= 0 {
+		return
+	}
+	if len(h.transactions) == h.size {
+		copy(h.transactions, h.transactions[1:])
+		h.transactions = h.transactions[:h.size-1]
+	}
+	h.transactions = append(h.transactions, transaction{id: id, update: update})
+}
+
+// lastTransactionID returns the id of the last committed transaction, or the
+// zero UUID if there is none
+func (h *transactionHistory) lastTransactionID() uuid.UUID {
+	if len(h.transactions) == 0 {
+		return uuid.Nil
+	}
+	return h.transactions[len(h.transactions)-1].id
+}
+
+// since returns the updates of the transactions committed after the one
+// identified by id, and whether that transaction is known
+func (h *transactionHistory) since(id uuid.UUID) ([]database.Update, bool) {
+	for i := len(h.transactions) - 1; i >= 0; i-- {
+		if h.transactions[i].id != id {
+			continue
+		}
+		updates := make([]database.Update, 0, len(h.transactions)-i-1)
+		for _, t := range h.transactions[i+1:] {
+			updates = append(updates, t.update)
+		}
+		return updates, true
+	}
+	return nil, false
+}
+
+// mergeUpdates merges a sequence of updates into a single one that brings
+// each row from its state before the first update to its state after the last
+// one. The merged updates are not modified.
+func mergeUpdates(dbModel model.DatabaseModel, sequence []database.Update) (database.Update, error) {
+	type change struct {
+		old model.Model
+		new model.Model
+	}
+	changes := map[string]map[string]*change{}
+	for _, update := range sequence {
+		for _, table := range update.GetUpdatedTables() {
+			if _, ok := changes[table]; !ok {
+				changes[table] = map[string]*change{}
+			}
+			_ = update.ForEachModelUpdate(table, func(uuid string, old, new model.Model) error {
+				if c, ok := changes[table][uuid]; ok {
+					c.new = new
+					return nil
+				}
+				changes[table][uuid] = &change{old: old, new: new}
+				return nil
+			})
+		}
+	}
+
+	merged := updates.ModelUpdates{}
+	for table, rows := range changes {
+		for uuid, c := range rows {
+			var op *ovsdb.Operation
+			switch {
+			case c.old == nil && c.new == nil:
+				// inserted and deleted in between
+				continue
+			case c.old == nil:
+				row, err := dbModel.NewRow(c.new)
+				if err != nil {
+					return nil, err
+				}
+				op = &ovsdb.Operation{Op: ovsdb.OperationInsert, Table: table, Row: row}
+			case c.new == nil:
+				op = &ovsdb.Operation{Op: ovsdb.OperationDelete, Table: table}
+			default:
+				row, err := dbModel.NewRow(c.new)
+				if err != nil {
+					return nil, err
+				}
+				op = &ovsdb.Operation{Op: ovsdb.OperationUpdate, Table: table, Row: row}
+			}
+			err := merged.AddOperation(dbModel, table, uuid, c.old, op)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return updates.NewDatabaseUpdate(merged, nil), nil
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/updates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionHistory(t *testing.T) {
+	h := newTransactionHistory(2)
+	assert.Equal(t, uuid.Nil, h.lastTransactionID())
+	_, found := h.since(uuid.Nil)
+	assert.False(t, found)
+
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	update := updates.NewDatabaseUpdate(updates.ModelUpdates{}, nil)
+	h.add(ids[0], update)
+	h.add(ids[1], update)
+	assert.Equal(t, ids[1], h.lastTransactionID())
+
+	since, found := h.since(ids[0])
+	assert.True(t, found)
+	assert.Equal(t, []database.Update{update}, since)
+	since, found = h.since(ids[1])
+	assert.True(t, found)
+	assert.Empty(t, since)
+
+	// the oldest transaction is forgotten once the history is full
+	h.add(ids[2], update)
+	assert.Equal(t, ids[2], h.lastTransactionID())
+	_, found = h.since(ids[0])
+	assert.False(t, found)
+	since, found = h.since(ids[1])
+	assert.True(t, found)
+	assert.Len(t, since, 1)
+}
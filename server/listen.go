@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+)
+
+const (
+	// defaultPort is the port listened on by ptcp and pssl endpoints that do
+	// not specify one
+	defaultPort = "6640"
+	// handshakeTimeout bounds the TLS handshake of a new connection
+	handshakeTimeout = 10 * time.Second
+	// peerCertificatesKey is the key of the peer certificates in the state
+	// of a client, see PeerCertificates
+	peerCertificatesKey = "peerCertificates"
+)
+
+// SetTLSConfig sets the TLS configuration of the pssl endpoints. Mutual
+// authentication is set up with its ClientAuth and ClientCAs fields.
+func (o *OvsdbServer) SetTLSConfig(cfg *tls.Config) {
+	o.readyMutex.Lock()
+	o.tlsConfig = cfg
+	o.readyMutex.Unlock()
+}
+
+// ServeEndpoints starts the OVSDB server on the given endpoints, in the
+// passive OVSDB connection format described in the ovsdb(7) man page:
+//
+//	ptcp:[port][:ip]  listens for TCP connections, on port 6640 by default
+//	pssl:[port][:ip]  listens for SSL connections, see SetTLSConfig
+//	punix:path        listens for connections on a Unix domain socket
+//
+// A port of 0 picks a free port, see Addrs. It returns when the server is
+// closed, or with the error of the first listener that fails, after closing
+// the server.
+func (o *OvsdbServer) ServeEndpoints(endpoints ...string) error {
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no endpoint to serve")
+	}
+	o.readyMutex.RLock()
+	tlsConfig := o.tlsConfig
+	o.readyMutex.RUnlock()
+	listeners := make([]net.Listener, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		l, err := listen(endpoint, tlsConfig)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, l)
+	}
+	return o.serve(listeners...)
+}
+
+// listen listens on a passive endpoint
+func listen(endpoint string, tlsConfig *tls.Config) (net.Listener, error) {
+	protocol, address, ok := strings.Cut(endpoint, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid endpoint %s", endpoint)
+	}
+	switch protocol {
+	case "ptcp", "pssl":
+		port, ip, _ := strings.Cut(address, ":")
+		if port == "" {
+			port = defaultPort
+		}
+		ip = strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]")
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+		if err != nil || protocol == "ptcp" {
+			return l, err
+		}
+		if tlsConfig == nil {
+			l.Close()
+			return nil, fmt.Errorf("endpoint %s requires a TLS configuration", endpoint)
+		}
+		return tls.NewListener(l, tlsConfig), nil
+	case "punix":
+		if address == "" {
+			return nil, fmt.Errorf("invalid endpoint %s: missing path", endpoint)
+		}
+		return net.Listen("unix", address)
+	}
+	return nil, fmt.Errorf("unknown network protocol %s", protocol)
+}
+
+// serve serves the connections accepted by the listeners until the server is
+// closed
+func (o *OvsdbServer) serve(listeners ...net.Listener) error {
+	o.readyMutex.Lock()
+	o.listeners = append(o.listeners, listeners...)
+	o.ready = true
+	o.readyMutex.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l net.Listener) {
+			defer wg.Done()
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					if o.Ready() {
+						errs <- err
+						o.Close()
+					}
+					return
+				}
+				go o.serveConn(conn)
+			}
+		}(l)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// serveConn serves a client connection
+func (o *OvsdbServer) serveConn(conn net.Conn) {
+	state := rpc2.NewState()
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		// the handshake is completed before the connect hooks run so that
+		// they can check the peer certificates
+		ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+		err := tlsConn.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			o.logger.V(3).Info("TLS handshake failed", "remote", conn.RemoteAddr().String(), "error", err.Error())
+			conn.Close()
+			return
+		}
+		state.Set(peerCertificatesKey, tlsConn.ConnectionState().PeerCertificates)
+	}
+	// the state of the client is released on disconnection, see
+	// releaseClient
+	o.srv.ServeCodecWithState(newJSONCodec(conn), state)
+}
+
+// Addrs returns the addresses the server listens on
+func (o *OvsdbServer) Addrs() []net.Addr {
+	o.readyMutex.RLock()
+	defer o.readyMutex.RUnlock()
+	addrs := make([]net.Addr, 0, len(o.listeners))
+	for _, l := range o.listeners {
+		addrs = append(addrs, l.Addr())
+	}
+	return addrs
+}
+
+// PeerCertificates returns the certificates presented by a client connected
+// over SSL, for the connect hooks (see OnConnect) to authorize it. It returns
+// nil for the other clients.
+func PeerCertificates(client *rpc2.Client) []*x509.Certificate {
+	if client == nil || client.State == nil {
+		return nil
+	}
+	certs, _ := client.State.Get(peerCertificatesKey)
+	peerCertificates, _ := certs.([]*x509.Certificate)
+	return peerCertificates
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/model"
+
+	. "github.com/ovn-org/libovsdb/test"
+)
+
+func TestListen(t *testing.T) {
+	tlsConfig := &tls.Config{}
+	socket := filepath.Join(t.TempDir(), "ovsdb.sock")
+	tests := []struct {
+		endpoint string
+		network  string
+		host     string
+		err      bool
+	}{
+		{endpoint: "ptcp:0:127.0.0.1", network: "tcp", host: "127.0.0.1"},
+		{endpoint: "ptcp:0:[127.0.0.1]", network: "tcp", host: "127.0.0.1"},
+		{endpoint: "pssl:0:127.0.0.1", network: "tcp", host: "127.0.0.1"},
+		{endpoint: "punix:" + socket, network: "unix", host: socket},
+		{endpoint: "punix:", err: true},
+		{endpoint: "tcp:127.0.0.1:0", err: true},
+		{endpoint: "ptcp", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			l, err := listen(tt.endpoint, tlsConfig)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer l.Close()
+			assert.Equal(t, tt.network, l.Addr().Network())
+			host := l.Addr().String()
+			if tt.network == "tcp" {
+				host, _, err = net.SplitHostPort(host)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.host, host)
+		})
+	}
+
+	// pssl endpoints require a TLS configuration
+	_, err := listen("pssl:0:127.0.0.1", nil)
+	assert.Error(t, err)
+}
+
+// newTestCertificate returns a certificate signed by the parent one, or a
+// self-signed CA certificate if there is no parent
+func newTestCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServeEndpoints(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	serverCert := newTestCertificate(t, "server", &ca)
+	clientCert := newTestCertificate(t, "client", &ca)
+
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	server, err := NewOvsdbServer(ovsDB, dbModel)
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetTLSConfig(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	peers := make(chan []*x509.Certificate, 10)
+	server.OnConnect(func(client *rpc2.Client) {
+		peers <- PeerCertificates(client)
+	})
+
+	socket := filepath.Join(t.TempDir(), "ovsdb.sock")
+	go func() {
+		if err := server.ServeEndpoints("pssl:0:127.0.0.1", "ptcp:0:127.0.0.1", "punix:"+socket); err != nil {
+			t.Error(err)
+		}
+	}()
+	require.Eventually(t, server.Ready, 1*time.Second, 10*time.Millisecond)
+	addrs := server.Addrs()
+	require.Len(t, addrs, 3)
+
+	connect := func(endpoint string, tlsConfig *tls.Config) error {
+		opts := []client.Option{client.WithEndpoint(endpoint)}
+		if tlsConfig != nil {
+			opts = append(opts, client.WithTLSConfig(tlsConfig))
+		}
+		ovs, err := client.NewOVSDBClient(dbModel.Client(), opts...)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := ovs.Connect(ctx); err != nil {
+			return err
+		}
+		ovs.Disconnect()
+		return nil
+	}
+
+	// the connect hooks get the certificate of the clients connected over
+	// SSL
+	err = connect(fmt.Sprintf("ssl:%s", addrs[0]), &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      pool,
+	})
+	require.NoError(t, err)
+	certs := <-peers
+	require.NotEmpty(t, certs)
+	assert.Equal(t, "client", certs[0].Subject.CommonName)
+
+	err = connect(fmt.Sprintf("tcp:%s", addrs[1]), nil)
+	require.NoError(t, err)
+	assert.Empty(t, <-peers)
+	err = connect(fmt.Sprintf("unix:%s", addrs[2]), nil)
+	require.NoError(t, err)
+	assert.Empty(t, <-peers)
+
+	// clients with no certificate are not served
+	err = connect(fmt.Sprintf("ssl:%s", addrs[0]), &tls.Config{RootCAs: pool})
+	assert.Error(t, err)
+	assert.Empty(t, peers)
+}
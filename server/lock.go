@@ -0,0 +1,126 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// lockManager keeps track of the locks requested by the clients of the
+// server as described in RFC 7047 section 4.1.8 and later.
+// Each lock has a queue of clients: the client at the head of the queue owns
+// the lock and the rest are waiting to be granted it, in order.
+type lockManager struct {
+	locks map[string][]*rpc2.Client
+	mutex sync.Mutex
+}
+
+// lockNotification is a locked or stolen notification that has to be sent
+// to a client as a consequence of a change in the ownership of a lock
+type lockNotification struct {
+	client *rpc2.Client
+	method string
+	id     string
+}
+
+func newLockManager() *lockManager {
+	return &lockManager{
+		locks: make(map[string][]*rpc2.Client),
+	}
+}
+
+func (l *lockManager) queued(client *rpc2.Client, id string) bool {
+	for _, c := range l.locks[id] {
+		if c == client {
+			return true
+		}
+	}
+	return false
+}
+
+// lock queues the client for the lock and returns whether the client has
+// been granted it immediately
+func (l *lockManager) lock(client *rpc2.Client, id string) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if disconnected(client) {
+		return false, errDisconnected
+	}
+	if l.queued(client, id) {
+		return false, fmt.Errorf("must issue unlock before new lock for %s", id)
+	}
+	l.locks[id] = append(l.locks[id], client)
+	return len(l.locks[id]) == 1, nil
+}
+
+// steal grants the lock to the client, preempting the current owner if any.
+// The previous owner stays queued for the lock and is notified that it has
+// been stolen.
+func (l *lockManager) steal(client *rpc2.Client, id string) ([]lockNotification, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if disconnected(client) {
+		return nil, errDisconnected
+	}
+	if l.queued(client, id) {
+		return nil, fmt.Errorf("must issue unlock before new steal for %s", id)
+	}
+	var notifications []lockNotification
+	if queue := l.locks[id]; len(queue) > 0 {
+		notifications = append(notifications, lockNotification{client: queue[0], method: "stolen", id: id})
+	}
+	l.locks[id] = append([]*rpc2.Client{client}, l.locks[id]...)
+	return notifications, nil
+}
+
+// unlock releases the lock or dequeues the client from it. If the client
+// owned the lock, it is granted to the next client waiting for it, which
+// needs to be notified.
+func (l *lockManager) unlock(client *rpc2.Client, id string) ([]lockNotification, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.queued(client, id) {
+		return nil, fmt.Errorf("unlock of %s without lock or steal", id)
+	}
+	return l.release(client, id), nil
+}
+
+// unlockAll releases all the locks that the client owns or is waiting for
+func (l *lockManager) unlockAll(client *rpc2.Client) []lockNotification {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var notifications []lockNotification
+	for id := range l.locks {
+		notifications = append(notifications, l.release(client, id)...)
+	}
+	return notifications
+}
+
+func (l *lockManager) release(client *rpc2.Client, id string) []lockNotification {
+	queue := l.locks[id]
+	for i, c := range queue {
+		if c != client {
+			continue
+		}
+		queue = append(queue[:i:i], queue[i+1:]...)
+		if len(queue) == 0 {
+			delete(l.locks, id)
+			return nil
+		}
+		l.locks[id] = queue
+		if i == 0 {
+			return []lockNotification{{client: queue[0], method: "locked", id: id}}
+		}
+		return nil
+	}
+	return nil
+}
+
+// owns returns whether the client owns the lock
+func (l *lockManager) owns(client *rpc2.Client, id string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	queue := l.locks[id]
+	return len(queue) > 0 && queue[0] == client
+}
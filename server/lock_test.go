@@ -0,0 +1,179 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ovn-org/libovsdb/test"
+)
+
+func TestLockManager(t *testing.T) {
+	c1 := &rpc2.Client{}
+	c2 := &rpc2.Client{}
+	c3 := &rpc2.Client{}
+	l := newLockManager()
+
+	locked, err := l.lock(c1, "foo")
+	require.NoError(t, err)
+	assert.True(t, locked)
+	assert.True(t, l.owns(c1, "foo"))
+
+	_, err = l.lock(c1, "foo")
+	assert.Error(t, err)
+
+	locked, err = l.lock(c2, "foo")
+	require.NoError(t, err)
+	assert.False(t, locked)
+	assert.False(t, l.owns(c2, "foo"))
+
+	_, err = l.steal(c2, "foo")
+	assert.Error(t, err)
+
+	notifications, err := l.steal(c3, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []lockNotification{{client: c1, method: "stolen", id: "foo"}}, notifications)
+	assert.True(t, l.owns(c3, "foo"))
+	assert.False(t, l.owns(c1, "foo"))
+
+	// the previous owner is still queued and regains the lock first
+	notifications, err = l.unlock(c3, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []lockNotification{{client: c1, method: "locked", id: "foo"}}, notifications)
+	assert.True(t, l.owns(c1, "foo"))
+
+	_, err = l.unlock(c3, "foo")
+	assert.Error(t, err)
+
+	locked, err = l.lock(c1, "bar")
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	notifications = l.unlockAll(c1)
+	assert.Equal(t, []lockNotification{{client: c2, method: "locked", id: "foo"}}, notifications)
+	assert.True(t, l.owns(c2, "foo"))
+	assert.False(t, l.owns(c1, "bar"))
+	assert.NotContains(t, l.locks, "bar")
+
+	notifications, err = l.unlock(c2, "foo")
+	require.NoError(t, err)
+	assert.Empty(t, notifications)
+	assert.Empty(t, l.locks)
+}
+
+type lockTestClient struct {
+	*rpc2.Client
+	notifications chan string
+}
+
+func newLockTestClient(t *testing.T, path string) *lockTestClient {
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	c := &lockTestClient{
+		Client:        rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn)),
+		notifications: make(chan string, 10),
+	}
+	for _, method := range []string{"locked", "stolen"} {
+		method := method
+		c.Handle(method, func(_ *rpc2.Client, args []interface{}, _ *[]interface{}) error {
+			c.notifications <- fmt.Sprintf("%s %v", method, args[0])
+			return nil
+		})
+	}
+	go c.Run()
+	return c
+}
+
+func (c *lockTestClient) lock(t *testing.T, method, id string) bool {
+	var reply ovsdb.LockResult
+	err := c.Call(method, ovsdb.NewLockArgs(id), &reply)
+	require.NoError(t, err)
+	return reply.Locked
+}
+
+func (c *lockTestClient) assert(t *testing.T, id string) string {
+	var reply []ovsdb.OperationResult
+	err := c.Call("transact", ovsdb.NewTransactArgs("Open_vSwitch", ovsdb.Operation{Op: ovsdb.OperationAssert, Lock: &id}), &reply)
+	require.NoError(t, err)
+	require.Len(t, reply, 1)
+	return reply[0].Error
+}
+
+func (c *lockTestClient) expectNotification(t *testing.T, notification string) {
+	select {
+	case n := <-c.notifications:
+		assert.Equal(t, notification, n)
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for notification %q", notification)
+	}
+}
+
+func TestOvsdbServerLock(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	server, err := NewOvsdbServer(ovsDB, dbModel)
+	require.NoError(t, err)
+
+	rand.Seed(time.Now().UnixNano())
+	tmpfile := fmt.Sprintf("/tmp/ovsdb-%d.sock", rand.Intn(10000))
+	defer os.Remove(tmpfile)
+	go func() {
+		if err := server.Serve("unix", tmpfile); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer server.Close()
+	require.Eventually(t, func() bool {
+		return server.Ready()
+	}, 1*time.Second, 10*time.Millisecond)
+
+	c1 := newLockTestClient(t, tmpfile)
+	defer c1.Close()
+	c2 := newLockTestClient(t, tmpfile)
+	defer c2.Close()
+
+	assert.True(t, c1.lock(t, "lock", "foo"))
+	assert.False(t, c2.lock(t, "lock", "foo"))
+	assert.Empty(t, c1.assert(t, "foo"))
+	assert.Equal(t, "not owner", c2.assert(t, "foo"))
+	assert.Equal(t, "not owner", c1.assert(t, "bar"))
+
+	err = c1.Call("lock", ovsdb.NewLockArgs("foo"), &ovsdb.LockResult{})
+	assert.Error(t, err)
+
+	// c2 is already queued for the lock and has to unlock it before stealing it
+	err = c2.Call("steal", ovsdb.NewLockArgs("foo"), &ovsdb.LockResult{})
+	assert.Error(t, err)
+	err = c2.Call("unlock", ovsdb.NewLockArgs("foo"), &struct{}{})
+	require.NoError(t, err)
+	assert.True(t, c2.lock(t, "steal", "foo"))
+	c1.expectNotification(t, "stolen foo")
+	assert.Equal(t, "not owner", c1.assert(t, "foo"))
+	assert.Empty(t, c2.assert(t, "foo"))
+
+	err = c2.Call("unlock", ovsdb.NewLockArgs("foo"), &struct{}{})
+	require.NoError(t, err)
+	c1.expectNotification(t, "locked foo")
+	assert.Empty(t, c1.assert(t, "foo"))
+
+	err = c2.Call("unlock", ovsdb.NewLockArgs("foo"), &struct{}{})
+	assert.Error(t, err)
+
+	// locks are released when the owner disconnects
+	assert.False(t, c2.lock(t, "lock", "foo"))
+	c1.Close()
+	c2.expectNotification(t, "locked foo")
+	assert.Empty(t, c2.assert(t, "foo"))
+}
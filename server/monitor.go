@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 
@@ -11,17 +12,96 @@ import (
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
 
+// defaultUpdateQueueSize is the number of update notifications that can be
+// queued for a client before it is deemed too slow and disconnected
+const defaultUpdateQueueSize = 1000
+
 // connectionMonitors maps a connection to a map or monitors
 type connectionMonitors struct {
 	monitors map[string]*monitor
 	mu       sync.RWMutex
+	client   *rpc2.Client
+	// updates queues the update notifications of the monitors, that are
+	// sent to the client in order without holding up the transactions
+	updates  chan notification
+	overflow sync.Once
+}
+
+// notification is an update notification queued for a client, or a barrier
+// closing flushed once the notifications queued before it are sent
+type notification struct {
+	method  string
+	args    []interface{}
+	flushed chan struct{}
 }
 
-func newConnectionMonitors() *connectionMonitors {
-	return &connectionMonitors{
+func newConnectionMonitors(client *rpc2.Client, queueSize int) *connectionMonitors {
+	c := &connectionMonitors{
 		monitors: make(map[string]*monitor),
 		mu:       sync.RWMutex{},
+		client:   client,
+		updates:  make(chan notification, queueSize),
 	}
+	go c.sendUpdates()
+	return c
+}
+
+// add adds a monitor of the connection
+func (c *connectionMonitors) add(m *monitor) {
+	m.updates = c
+	c.monitors[m.id] = m
+}
+
+// queue queues an update notification for the client and returns whether it
+// was queued. A client whose queue is full is disconnected, so that it does
+// not hold up the server, and gets the updates it missed when it monitors the
+// database again.
+func (c *connectionMonitors) queue(n notification) bool {
+	select {
+	case c.updates <- n:
+		return true
+	default:
+		c.overflow.Do(func() {
+			log.Printf("client is too slow to handle update notifications, disconnecting")
+			if c.client != nil {
+				c.client.Close()
+			}
+		})
+		return false
+	}
+}
+
+// flush returns a channel that is closed once the update notifications
+// queued so far are sent, or have failed because the client is gone
+func (c *connectionMonitors) flush() <-chan struct{} {
+	flushed := make(chan struct{})
+	if !c.queue(notification{flushed: flushed}) {
+		close(flushed)
+	}
+	return flushed
+}
+
+// sendUpdates sends the queued update notifications until the monitors of the
+// connection are released once the client disconnects
+func (c *connectionMonitors) sendUpdates() {
+	for n := range c.updates {
+		if n.flushed != nil {
+			close(n.flushed)
+			continue
+		}
+		var reply interface{}
+		err := c.client.Call(n.method, n.args, &reply)
+		// the remaining notifications fail once the client is gone
+		if err != nil && err != rpc2.ErrShutdown {
+			log.Printf("client error handling %s rpc: %v", n.method, err)
+		}
+	}
+}
+
+// release stops sending update notifications once the queued ones are sent.
+// No notification can be queued afterwards.
+func (c *connectionMonitors) release() {
+	close(c.updates)
 }
 
 // monitor represents a connection to a client where db changes
@@ -30,7 +110,10 @@ type monitor struct {
 	id      string
 	kind    monitorKind
 	request map[string]*ovsdb.MonitorRequest
+	schema  *ovsdb.DatabaseSchema
 	client  *rpc2.Client
+	// updates queues the update notifications of the monitor
+	updates *connectionMonitors
 }
 
 type monitorKind int
@@ -41,34 +124,120 @@ const (
 	monitorKindConditionalSince
 )
 
-func newMonitor(id string, request map[string]*ovsdb.MonitorRequest, client *rpc2.Client) *monitor {
+func newMonitor(id string, request map[string]*ovsdb.MonitorRequest, schema *ovsdb.DatabaseSchema, client *rpc2.Client) *monitor {
 	m := &monitor{
 		id:      id,
 		kind:    monitorKindOriginal,
 		request: request,
+		schema:  schema,
 		client:  client,
 	}
 	return m
 }
 
-func newConditionalMonitor(id string, request map[string]*ovsdb.MonitorRequest, client *rpc2.Client) *monitor {
+func newConditionalMonitor(id string, request map[string]*ovsdb.MonitorRequest, schema *ovsdb.DatabaseSchema, client *rpc2.Client) (*monitor, error) {
 	m := &monitor{
 		id:      id,
 		kind:    monitorKindConditional,
 		request: request,
+		schema:  schema,
 		client:  client,
 	}
-	return m
+	if err := m.checkConditions(); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func newConditionalSinceMonitor(id string, request map[string]*ovsdb.MonitorRequest, client *rpc2.Client) *monitor {
+func newConditionalSinceMonitor(id string, request map[string]*ovsdb.MonitorRequest, schema *ovsdb.DatabaseSchema, client *rpc2.Client) (*monitor, error) {
 	m := &monitor{
 		id:      id,
-		kind:    monitorKindConditional,
+		kind:    monitorKindConditionalSince,
 		request: request,
+		schema:  schema,
 		client:  client,
 	}
-	return m
+	if err := m.checkConditions(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkConditions verifies that the conditions of the monitor refer to
+// existing tables and columns, and that their values have the right type
+func (m *monitor) checkConditions() error {
+	for table, request := range m.request {
+		if request == nil || len(request.Where) == 0 {
+			continue
+		}
+		tableSchema := m.schema.Table(table)
+		if tableSchema == nil {
+			return fmt.Errorf("table %s not found in schema", table)
+		}
+		for _, condition := range request.Where {
+			column := tableSchema.Column(condition.Column)
+			if column == nil {
+				return fmt.Errorf("column %s not found in table %s", condition.Column, table)
+			}
+			if _, err := ovsdb.OvsToNative(column, condition.Value); err != nil {
+				return fmt.Errorf("invalid condition %s on table %s: %w", condition, table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matches returns whether the row satisfies the conditions of the monitor for
+// the table. As described in ovsdb-server(7), a row satisfies the conditions
+// if it satisfies any of them, and any row satisfies an empty list of
+// conditions.
+func (m *monitor) matches(table string, row *ovsdb.Row) bool {
+	request := m.request[table]
+	if request == nil || len(request.Where) == 0 {
+		return true
+	}
+	if row == nil {
+		return false
+	}
+	tableSchema := m.schema.Table(table)
+	for _, condition := range request.Where {
+		column := tableSchema.Column(condition.Column)
+		value, err := ovsdb.OvsToNative(column, (*row)[condition.Column])
+		if err != nil {
+			continue
+		}
+		conditionValue, err := ovsdb.OvsToNative(column, condition.Value)
+		if err != nil {
+			continue
+		}
+		if ok, err := condition.Function.Evaluate(value, conditionValue); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConditions2 adapts a row update to the conditions of the monitor for
+// the table: an update of a row that starts satisfying the conditions is
+// turned into an insert, and an update of a row that stops satisfying them
+// is turned into a delete. Returns nil if the row satisfies the conditions
+// neither before nor after the update.
+func (m *monitor) applyConditions2(table string, ru2 ovsdb.RowUpdate2) *ovsdb.RowUpdate2 {
+	if request := m.request[table]; request == nil || len(request.Where) == 0 {
+		return &ru2
+	}
+	oldMatches := ru2.Old != nil && m.matches(table, ru2.Old)
+	newMatches := ru2.New != nil && m.matches(table, ru2.New)
+	switch {
+	case oldMatches && newMatches:
+		return &ru2
+	case newMatches:
+		return &ovsdb.RowUpdate2{Insert: ru2.New, New: ru2.New}
+	case oldMatches:
+		return &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}, Old: ru2.Old}
+	default:
+		return nil
+	}
 }
 
 // Send will send an update if it matches the tables and monitor select arguments
@@ -81,11 +250,7 @@ func (m *monitor) Send(update database.Update) {
 		return
 	}
 	args := []interface{}{json.RawMessage([]byte(m.id)), tu}
-	var reply interface{}
-	err := m.client.Call("update2", args, &reply)
-	if err != nil {
-		log.Printf("client error handling update rpc: %v", err)
-	}
+	m.updates.queue(notification{method: "update2", args: args})
 }
 
 // Send2 will send an update if it matches the tables and monitor select arguments
@@ -94,15 +259,17 @@ func (m *monitor) Send(update database.Update) {
 func (m *monitor) Send2(update database.Update) {
 	// remove updates for tables that we aren't watching
 	tu := m.filter2(update)
+	m.SendUpdates2(tu)
+}
+
+// SendUpdates2 will send the provided updates, if any, in an update2
+// notification
+func (m *monitor) SendUpdates2(tu ovsdb.TableUpdates2) {
 	if len(tu) == 0 {
 		return
 	}
 	args := []interface{}{json.RawMessage([]byte(m.id)), tu}
-	var reply interface{}
-	err := m.client.Call("update2", args, &reply)
-	if err != nil {
-		log.Printf("client error handling update2 rpc: %v", err)
-	}
+	m.updates.queue(notification{method: "update2", args: args})
 }
 
 // Send3 will send an update if it matches the tables and monitor select arguments
@@ -115,11 +282,7 @@ func (m *monitor) Send3(id uuid.UUID, update database.Update) {
 		return
 	}
 	args := []interface{}{json.RawMessage([]byte(m.id)), id.String(), tu}
-	var reply interface{}
-	err := m.client.Call("update2", args, &reply)
-	if err != nil {
-		log.Printf("client error handling update3 rpc: %v", err)
-	}
+	m.updates.queue(notification{method: "update3", args: args})
 }
 
 func filterColumns(row *ovsdb.Row, columns map[string]bool) *ovsdb.Row {
@@ -190,7 +353,11 @@ func (m *monitor) filter2(update database.Update) ovsdb.TableUpdates2 {
 		for _, c := range m.request[table].Columns {
 			cols[c] = true
 		}
-		_ = update.ForEachRowUpdate(table, func(uuid string, ru2 ovsdb.RowUpdate2) error {
+		_ = update.ForEachRowUpdate(table, func(uuid string, update ovsdb.RowUpdate2) error {
+			ru2 := m.applyConditions2(table, update)
+			if ru2 == nil {
+				return nil
+			}
 			switch {
 			case ru2.Insert != nil && m.request[table].Select.Insert():
 				fallthrough
@@ -203,7 +370,7 @@ func (m *monitor) filter2(update database.Update) ovsdb.TableUpdates2 {
 				ru2.Insert = filterColumns(ru2.Insert, cols)
 				ru2.Modify = filterColumns(ru2.Modify, cols)
 				ru2.Delete = filterColumns(ru2.Delete, cols)
-				tu2[uuid] = &ru2
+				tu2[uuid] = ru2
 			}
 			return nil
 		})
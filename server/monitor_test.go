@@ -2,6 +2,7 @@ package server
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/ovn-org/libovsdb/test"
@@ -180,3 +181,96 @@ func TestMonitorFilter2(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitorApplyConditions2(t *testing.T) {
+	dbModel, err := test.GetModel()
+	assert.NoError(t, err)
+	monitor := monitor{
+		request: map[string]*ovsdb.MonitorRequest{
+			"Bridge": {
+				Columns: []string{"name"},
+				Where: []ovsdb.Condition{
+					ovsdb.NewCondition("name", ovsdb.ConditionEqual, "foo"),
+					ovsdb.NewCondition("datapath_type", ovsdb.ConditionEqual, "netdev"),
+				},
+				Select: ovsdb.NewDefaultMonitorSelect(),
+			},
+		},
+		schema: &dbModel.Schema,
+	}
+	assert.NoError(t, monitor.checkConditions())
+
+	fooRow := ovsdb.Row{"name": "foo", "datapath_type": "system"}
+	barRow := ovsdb.Row{"name": "bar", "datapath_type": "system"}
+	netdevRow := ovsdb.Row{"name": "bar", "datapath_type": "netdev"}
+	tests := []struct {
+		name     string
+		update   ovsdb.RowUpdate2
+		expected *ovsdb.RowUpdate2
+	}{
+		{
+			"insert matching",
+			ovsdb.RowUpdate2{Insert: &fooRow, New: &fooRow},
+			&ovsdb.RowUpdate2{Insert: &fooRow, New: &fooRow},
+		},
+		{
+			"insert not matching",
+			ovsdb.RowUpdate2{Insert: &barRow, New: &barRow},
+			nil,
+		},
+		{
+			"modify matching",
+			ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "netdev"}, Old: &fooRow, New: &netdevRow},
+			&ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "netdev"}, Old: &fooRow, New: &netdevRow},
+		},
+		{
+			"modify entering conditions",
+			ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "netdev"}, Old: &barRow, New: &netdevRow},
+			&ovsdb.RowUpdate2{Insert: &netdevRow, New: &netdevRow},
+		},
+		{
+			"modify leaving conditions",
+			ovsdb.RowUpdate2{Modify: &ovsdb.Row{"datapath_type": "system"}, Old: &netdevRow, New: &barRow},
+			&ovsdb.RowUpdate2{Delete: &ovsdb.Row{}, Old: &netdevRow},
+		},
+		{
+			"modify not matching",
+			ovsdb.RowUpdate2{Modify: &ovsdb.Row{"name": "bar"}, Old: &barRow, New: &barRow},
+			nil,
+		},
+		{
+			"delete matching",
+			ovsdb.RowUpdate2{Delete: &ovsdb.Row{}, Old: &fooRow},
+			&ovsdb.RowUpdate2{Delete: &ovsdb.Row{}, Old: &fooRow},
+		},
+		{
+			"delete not matching",
+			ovsdb.RowUpdate2{Delete: &ovsdb.Row{}, Old: &barRow},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, monitor.applyConditions2("Bridge", tt.update))
+		})
+	}
+}
+
+func TestConnectionMonitorsFlush(t *testing.T) {
+	c := newConnectionMonitors(nil, 2)
+	defer c.release()
+
+	first := c.flush()
+	second := c.flush()
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("the queue was not flushed")
+	}
+	// the notifications are sent in order
+	select {
+	case <-first:
+	default:
+		t.Fatal("the queue was flushed out of order")
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,9 +10,9 @@ import (
 	"net"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/cenkalti/rpc2"
-	"github.com/cenkalti/rpc2/jsonrpc"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/stdr"
 	"github.com/google/uuid"
@@ -22,7 +24,8 @@ import (
 // OvsdbServer is an ovsdb server
 type OvsdbServer struct {
 	srv          *rpc2.Server
-	listener     net.Listener
+	listeners    []net.Listener
+	tlsConfig    *tls.Config
 	done         chan struct{}
 	db           database.Database
 	ready        bool
@@ -32,49 +35,93 @@ type OvsdbServer struct {
 	modelsMutex  sync.RWMutex
 	monitors     map[*rpc2.Client]*connectionMonitors
 	monitorMutex sync.RWMutex
+	locks        *lockManager
+	history      map[string]*transactionHistory
 	logger       logr.Logger
-	txnMutex     sync.Mutex
+	// txnLocks serialize the transactions on each database, see
+	// lockTransactions
+	txnLocks map[string]chan struct{}
+	waiters  *waiters
+	// maintainsServerDatabase is whether the server maintains the _Server
+	// database, rather than it being provided to NewOvsdbServer
+	maintainsServerDatabase bool
+	serverID                uuid.UUID
+	// clustered holds the databases reported as clustered, see SetLeader
+	clustered      map[string]bool
+	clusteredMutex sync.Mutex
+	// updateQueueSize is the number of update notifications that can be
+	// queued for a client, see SetUpdateQueueSize
+	updateQueueSize int
 }
 
 func init() {
 	stdr.SetVerbosity(5)
 }
 
-// NewOvsdbServer returns a new OvsdbServer
+// NewOvsdbServer returns a new OvsdbServer. Unless its model is provided, the
+// server also serves the _Server database, that reports the status of its
+// databases, see SetLeader. Clients can only read it.
 func NewOvsdbServer(db database.Database, models ...model.DatabaseModel) (*OvsdbServer, error) {
 	l := stdr.NewWithOptions(log.New(os.Stderr, "", log.LstdFlags), stdr.Options{LogCaller: stdr.All}).WithName("server")
 	o := &OvsdbServer{
-		done:         make(chan struct{}, 1),
-		doEcho:       true,
-		db:           db,
-		models:       make(map[string]model.DatabaseModel),
-		modelsMutex:  sync.RWMutex{},
-		monitors:     make(map[*rpc2.Client]*connectionMonitors),
-		monitorMutex: sync.RWMutex{},
-		logger:       l,
+		done:            make(chan struct{}, 1),
+		doEcho:          true,
+		db:              db,
+		models:          make(map[string]model.DatabaseModel),
+		modelsMutex:     sync.RWMutex{},
+		monitors:        make(map[*rpc2.Client]*connectionMonitors),
+		monitorMutex:    sync.RWMutex{},
+		locks:           newLockManager(),
+		history:         make(map[string]*transactionHistory),
+		logger:          l,
+		txnLocks:        make(map[string]chan struct{}),
+		waiters:         newWaiters(),
+		serverID:        uuid.New(),
+		clustered:       make(map[string]bool),
+		updateQueueSize: defaultUpdateQueueSize,
 	}
 	o.modelsMutex.Lock()
 	for _, model := range models {
 		o.models[model.Schema.Name] = model
 	}
+	if _, ok := o.models[serverDatabaseName]; !ok {
+		clientDBModel, dbModel, err := serverDatabaseModel()
+		if err != nil {
+			o.modelsMutex.Unlock()
+			return nil, err
+		}
+		o.models[serverDatabaseName] = dbModel
+		o.db = newServerDatabase(db, clientDBModel)
+		o.maintainsServerDatabase = true
+	}
 	o.modelsMutex.Unlock()
 	for database, model := range o.models {
 		if err := o.db.CreateDatabase(database, model.Schema); err != nil {
 			return nil, err
 		}
+		o.history[database] = newTransactionHistory(defaultHistorySize)
+		o.txnLocks[database] = make(chan struct{}, 1)
+	}
+	if o.maintainsServerDatabase {
+		if err := o.addServerDatabaseRows(); err != nil {
+			return nil, err
+		}
 	}
 	o.srv = rpc2.NewServer()
 	o.srv.Handle("list_dbs", o.ListDatabases)
 	o.srv.Handle("get_schema", o.GetSchema)
-	o.srv.Handle("transact", o.Transact)
+	o.srv.Handle("transact", o.transactRequest)
 	o.srv.Handle("cancel", o.Cancel)
 	o.srv.Handle("monitor", o.Monitor)
 	o.srv.Handle("monitor_cond", o.MonitorCond)
 	o.srv.Handle("monitor_cond_since", o.MonitorCondSince)
+	o.srv.Handle("monitor_cond_change", o.MonitorCondChange)
 	o.srv.Handle("monitor_cancel", o.MonitorCancel)
+	o.srv.Handle("lock", o.Lock)
 	o.srv.Handle("steal", o.Steal)
 	o.srv.Handle("unlock", o.Unlock)
 	o.srv.Handle("echo", o.Echo)
+	o.srv.OnDisconnect(o.releaseClient)
 	return o, nil
 }
 
@@ -94,28 +141,36 @@ func (o *OvsdbServer) DoEcho(ok bool) {
 	o.readyMutex.Unlock()
 }
 
-// Serve starts the OVSDB server on the given path and protocol
+// SetUpdateQueueSize sets the number of update notifications that can be
+// queued for a client before it is deemed too slow and disconnected. It
+// applies to the clients that monitor a database afterwards.
+func (o *OvsdbServer) SetUpdateQueueSize(size int) {
+	o.monitorMutex.Lock()
+	o.updateQueueSize = size
+	o.monitorMutex.Unlock()
+}
+
+// Serve starts the OVSDB server on the given path and protocol. See
+// ServeEndpoints to serve OVSDB connection strings.
 func (o *OvsdbServer) Serve(protocol string, path string) error {
-	var err error
-	o.listener, err = net.Listen(protocol, path)
+	l, err := net.Listen(protocol, path)
 	if err != nil {
 		return err
 	}
-	o.readyMutex.Lock()
-	o.ready = true
-	o.readyMutex.Unlock()
-	for {
-		conn, err := o.listener.Accept()
-		if err != nil {
-			if !o.Ready() {
-				return nil
-			}
-			return err
-		}
+	return o.serve(l)
+}
 
-		// TODO: Need to cleanup when connection is closed
-		go o.srv.ServeCodec(jsonrpc.NewJSONCodec(conn))
-	}
+// errDisconnected is returned by the handlers of requests that would keep
+// state for a client that has already disconnected
+var errDisconnected = errors.New("client disconnected")
+
+// disconnected returns whether the connection to the client is closed, in
+// which case its state is released, or about to be released, by
+// releaseClient. Handlers that keep state for the client check it while
+// holding the lock that protects that state, so that the state cannot be
+// kept once it has been released.
+func disconnected(client *rpc2.Client) bool {
+	return client != nil && isClosed(client.DisconnectNotify())
 }
 
 func isClosed(ch <-chan struct{}) bool {
@@ -132,16 +187,19 @@ func isClosed(ch <-chan struct{}) bool {
 func (o *OvsdbServer) Close() {
 	o.readyMutex.Lock()
 	o.ready = false
+	listeners := o.listeners
+	o.listeners = nil
+	// Close might be called concurrently, from serve on an accept failure
+	if !isClosed(o.done) {
+		close(o.done)
+	}
 	o.readyMutex.Unlock()
-	// Only close the listener if Serve() has been called
-	if o.listener != nil {
-		if err := o.listener.Close(); err != nil {
+	// Only close the listeners if Serve() has been called
+	for _, l := range listeners {
+		if err := l.Close(); err != nil {
 			o.logger.Error(err, "failed to close listener")
 		}
 	}
-	if !isClosed(o.done) {
-		close(o.done)
-	}
 }
 
 // Ready returns true if a server is ready to handle connections
@@ -179,14 +237,40 @@ func (o *OvsdbServer) GetSchema(client *rpc2.Client, args []interface{}, reply *
 	return nil
 }
 
+// lockTransactions waits until no other transaction is processed on the
+// database. Transactions on different databases are processed concurrently.
+// There are no transactions to wait for on a database the server does not
+// serve.
+func (o *OvsdbServer) lockTransactions(db string) {
+	if txnLock, ok := o.txnLocks[db]; ok {
+		txnLock <- struct{}{}
+	}
+}
+
+// unlockTransactions lets the next transaction on the database be processed
+func (o *OvsdbServer) unlockTransactions(db string) {
+	if txnLock, ok := o.txnLocks[db]; ok {
+		<-txnLock
+	}
+}
+
 // Transact issues a new database transaction and returns the results
 func (o *OvsdbServer) Transact(client *rpc2.Client, args []json.RawMessage, reply *[]*ovsdb.OperationResult) error {
-	// While allowing other rpc handlers to run in parallel, this ovsdb server expects transactions
-	// to be serialized. The following mutex ensures that.
-	// Ref: https://github.com/cenkalti/rpc2/blob/c1acbc6ec984b7ae6830b6a36b62f008d5aefc4c/client.go#L187
-	o.txnMutex.Lock()
-	defer o.txnMutex.Unlock()
+	return o.transactWithContext(context.Background(), client, args, reply)
+}
 
+// transactRequest handles a transact request, which a cancel request
+// referring to its id, or the disconnection of the client, aborts while it
+// is in progress
+func (o *OvsdbServer) transactRequest(client *rpc2.Client, request *TransactRequest, reply *[]*ovsdb.OperationResult) error {
+	defer request.Done()
+	return o.transactWithContext(request.Context(), client, request.Args, reply)
+}
+
+// transactWithContext issues a new database transaction that fails with a
+// "canceled" error if the context is done while it waits for its turn or is
+// blocked in a wait operation
+func (o *OvsdbServer) transactWithContext(ctx context.Context, client *rpc2.Client, args []json.RawMessage, reply *[]*ovsdb.OperationResult) error {
 	if len(args) < 2 {
 		return fmt.Errorf("not enough args")
 	}
@@ -202,29 +286,120 @@ func (o *OvsdbServer) Transact(client *rpc2.Client, args []json.RawMessage, repl
 		if err != nil {
 			return err
 		}
+		// the _Server database maintained by the server is only updated
+		// by the server itself, through processTransaction
+		if db == serverDatabaseName && o.maintainsServerDatabase && !readOnlyOperation(op) {
+			return fmt.Errorf("database %s is read-only", db)
+		}
 		ops = append(ops, op)
 	}
-	response, updates := o.transact(db, ops)
+
+	// A transaction with a wait operation that is neither satisfied nor
+	// timed out is suspended, without holding up the other transactions,
+	// and processed again once a commit changes the table waited on or the
+	// operation times out.
+	start := time.Now()
+	for {
+		waiter, err := o.processTransaction(ctx, client, db, ops, start, reply)
+		if err != nil || waiter == nil {
+			return err
+		}
+		select {
+		case <-waiter.wakeup:
+			o.waiters.remove(waiter)
+		case <-ctx.Done():
+			o.waiters.remove(waiter)
+			return &ovsdb.Canceled{}
+		}
+	}
+}
+
+// readOnlyOperation returns whether an operation leaves the database unchanged
+func readOnlyOperation(op ovsdb.Operation) bool {
+	switch op.Op {
+	case ovsdb.OperationSelect, ovsdb.OperationWait, ovsdb.OperationComment, ovsdb.OperationAssert, ovsdb.OperationAbort:
+		return true
+	}
+	return false
+}
+
+// processTransaction processes and commits a transaction issued at start. If
+// a wait operation blocks it, nothing is committed and the waiter to process
+// it again with is returned.
+func (o *OvsdbServer) processTransaction(ctx context.Context, client *rpc2.Client, db string, ops []ovsdb.Operation, start time.Time, reply *[]*ovsdb.OperationResult) (*waiter, error) {
+	// While allowing other rpc handlers to run in parallel, this ovsdb server expects the transactions
+	// on a database to be serialized. The following lock ensures that.
+	// Ref: https://github.com/cenkalti/rpc2/blob/c1acbc6ec984b7ae6830b6a36b62f008d5aefc4c/client.go#L187
+	// A canceled transaction gives up waiting for its turn, unless it does
+	// not have to wait.
+	if txnLock, ok := o.txnLocks[db]; ok {
+		select {
+		case txnLock <- struct{}{}:
+		default:
+			select {
+			case txnLock <- struct{}{}:
+			case <-ctx.Done():
+				return nil, &ovsdb.Canceled{}
+			}
+		}
+		defer o.unlockTransactions(db)
+	}
+
+	var waiter *waiter
+	blocked := func(table string, deadline time.Time) {
+		// the waiter is added while no commit can happen so that none is
+		// missed
+		waiter = o.waiters.add(db, table, deadline)
+	}
+	response, updates := o.transact(client, db, ops, database.WithContext(ctx), database.WithStart(start), database.WithWaitBlocked(blocked))
+	if waiter != nil {
+		return waiter, nil
+	}
+	// once its operations are processed, a transaction is committed and
+	// replied to even if it is canceled in the meantime, as per RFC 7047
+	// 4.1.4
 	*reply = response
 	for _, operResult := range response {
 		if operResult.Error != "" {
 			o.logger.Error(errors.New("failed to process operation"), "Skipping transaction DB commit due to error", "operations", ops, "results", response, "operation error", operResult.Error)
-			return nil
+			return nil, nil
 		}
 	}
 	transactionID := uuid.New()
-	o.processMonitors(transactionID, updates)
-	return o.db.Commit(db, transactionID, updates)
+	if err := o.db.Commit(db, transactionID, updates); err != nil {
+		return nil, err
+	}
+	if history, ok := o.history[db]; ok {
+		history.add(transactionID, updates)
+	}
+	if err := o.updateServerDatabaseIndex(db); err != nil {
+		o.logger.Error(err, "failed to update the index of the database", "database", db)
+	}
+	o.processMonitors(db, transactionID, updates)
+	o.waiters.wake(db, updates.GetUpdatedTables())
+	return nil, nil
 }
 
-func (o *OvsdbServer) transact(name string, operations []ovsdb.Operation) ([]*ovsdb.OperationResult, database.Update) {
-	transaction := o.db.NewTransaction(name)
+func (o *OvsdbServer) transact(client *rpc2.Client, name string, operations []ovsdb.Operation, opts ...database.TransactionOption) ([]*ovsdb.OperationResult, database.Update) {
+	lockOwner := func(lock string) bool {
+		return o.locks.owns(client, lock)
+	}
+	opts = append(opts, database.WithLockOwner(lockOwner))
+	transaction := o.db.NewTransaction(name, opts...)
 	return transaction.Transact(operations...)
 }
 
-// Cancel cancels the last transaction
+// Cancel aborts the transaction in progress that the client issued with the
+// provided request id, which then fails with a "canceled" error. Per RFC 7047
+// a cancel request is a notification, and requests that are not in progress
+// are ignored. The request is processed by the codec of the connection as it
+// is read, see jsonCodec, so this only checks its arguments.
 func (o *OvsdbServer) Cancel(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
-	return fmt.Errorf("not implemented")
+	if len(args) != 1 {
+		return fmt.Errorf("cancel expects a single request id")
+	}
+	*reply = []interface{}{}
+	return nil
 }
 
 // Monitor monitors a given database table and provides updates to the client via an RPC callback
@@ -241,11 +416,18 @@ func (o *OvsdbServer) Monitor(client *rpc2.Client, args []json.RawMessage, reply
 	if err := json.Unmarshal(args[2], &request); err != nil {
 		return err
 	}
+	// transactions must not be processed until the monitor is set up so that
+	// the client neither misses nor gets duplicated updates
+	o.lockTransactions(db)
+	defer o.unlockTransactions(db)
 	o.monitorMutex.Lock()
 	defer o.monitorMutex.Unlock()
+	if disconnected(client) {
+		return errDisconnected
+	}
 	clientMonitors, ok := o.monitors[client]
 	if !ok {
-		o.monitors[client] = newConnectionMonitors()
+		o.monitors[client] = newConnectionMonitors(client, o.updateQueueSize)
 	} else {
 		if _, ok := clientMonitors.monitors[value]; ok {
 			return fmt.Errorf("monitor with that value already exists")
@@ -269,7 +451,7 @@ func (o *OvsdbServer) Monitor(client *rpc2.Client, args []json.RawMessage, reply
 		}
 	}
 	*reply = tableUpdates
-	o.monitors[client].monitors[value] = newMonitor(value, request, client)
+	o.monitors[client].add(newMonitor(value, request, o.schema(db), client))
 	return nil
 }
 
@@ -287,35 +469,30 @@ func (o *OvsdbServer) MonitorCond(client *rpc2.Client, args []json.RawMessage, r
 	if err := json.Unmarshal(args[2], &request); err != nil {
 		return err
 	}
+	// transactions must not be processed until the monitor is set up so that
+	// the client neither misses nor gets duplicated updates
+	o.lockTransactions(db)
+	defer o.unlockTransactions(db)
 	o.monitorMutex.Lock()
 	defer o.monitorMutex.Unlock()
+	if disconnected(client) {
+		return errDisconnected
+	}
 	clientMonitors, ok := o.monitors[client]
 	if !ok {
-		o.monitors[client] = newConnectionMonitors()
+		o.monitors[client] = newConnectionMonitors(client, o.updateQueueSize)
 	} else {
 		if _, ok := clientMonitors.monitors[value]; ok {
 			return fmt.Errorf("monitor with that value already exists")
 		}
 	}
 
-	transaction := o.db.NewTransaction(db)
-
-	tableUpdates := make(ovsdb.TableUpdates2)
-	for t, request := range request {
-		op := ovsdb.Operation{Op: ovsdb.OperationSelect, Table: t, Columns: request.Columns}
-		result, _ := transaction.Transact(op)
-		if len(result) == 0 || len(result[0].Rows) == 0 {
-			continue
-		}
-		rows := result[0].Rows
-		tableUpdates[t] = make(ovsdb.TableUpdate2, len(rows))
-		for i := range rows {
-			uuid := rows[i]["_uuid"].(ovsdb.UUID).GoUUID
-			tableUpdates[t][uuid] = &ovsdb.RowUpdate2{Initial: &rows[i]}
-		}
+	monitor, err := newConditionalMonitor(value, request, o.schema(db), client)
+	if err != nil {
+		return err
 	}
-	*reply = tableUpdates
-	o.monitors[client].monitors[value] = newConditionalMonitor(value, request, client)
+	*reply = o.initialUpdates2(db, monitor)
+	o.monitors[client].add(monitor)
 	return nil
 }
 
@@ -333,56 +510,357 @@ func (o *OvsdbServer) MonitorCondSince(client *rpc2.Client, args []json.RawMessa
 	if err := json.Unmarshal(args[2], &request); err != nil {
 		return err
 	}
+	var lastTransactionID uuid.UUID
+	if len(args) > 3 {
+		var id string
+		if err := json.Unmarshal(args[3], &id); err != nil {
+			return fmt.Errorf("last transaction id %v is not a string", args[3])
+		}
+		// an unknown id just means that the client gets all the rows
+		lastTransactionID, _ = uuid.Parse(id)
+	}
+
+	// transactions must not be processed until the monitor is set up so that
+	// the client neither misses nor gets duplicated updates
+	o.lockTransactions(db)
+	defer o.unlockTransactions(db)
 	o.monitorMutex.Lock()
 	defer o.monitorMutex.Unlock()
+	if disconnected(client) {
+		return errDisconnected
+	}
 	clientMonitors, ok := o.monitors[client]
 	if !ok {
-		o.monitors[client] = newConnectionMonitors()
+		o.monitors[client] = newConnectionMonitors(client, o.updateQueueSize)
 	} else {
 		if _, ok := clientMonitors.monitors[value]; ok {
 			return fmt.Errorf("monitor with that value already exists")
 		}
 	}
 
+	monitor, err := newConditionalSinceMonitor(value, request, o.schema(db), client)
+	if err != nil {
+		return err
+	}
+	history := o.history[db]
+	tableUpdates, found, err := o.updatesSince2(db, monitor, lastTransactionID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		tableUpdates = o.initialUpdates2(db, monitor)
+	}
+	*reply = ovsdb.MonitorCondSinceReply{Found: found, LastTransactionID: history.lastTransactionID().String(), Updates: tableUpdates}
+	o.monitors[client].add(monitor)
+	return nil
+}
+
+// updatesSince2 returns the changes to the rows that match the monitor
+// requests since the transaction identified by id, and whether that
+// transaction is known to the transaction history of the database
+func (o *OvsdbServer) updatesSince2(db string, m *monitor, id uuid.UUID) (ovsdb.TableUpdates2, bool, error) {
+	since, found := o.history[db].since(id)
+	if !found {
+		return nil, false, nil
+	}
+	o.modelsMutex.RLock()
+	dbModel := o.models[db]
+	o.modelsMutex.RUnlock()
+	update, err := mergeUpdates(dbModel, since)
+	if err != nil {
+		return nil, false, err
+	}
+	return m.filter2(update), true, nil
+}
+
+func (o *OvsdbServer) schema(db string) *ovsdb.DatabaseSchema {
+	o.modelsMutex.RLock()
+	defer o.modelsMutex.RUnlock()
+	schema := o.models[db].Schema
+	return &schema
+}
+
+// initialUpdates2 returns the rows of the tables that match the monitor
+// requests, with the requested columns, as initial updates
+func (o *OvsdbServer) initialUpdates2(db string, m *monitor) ovsdb.TableUpdates2 {
 	transaction := o.db.NewTransaction(db)
 
 	tableUpdates := make(ovsdb.TableUpdates2)
-	for t, request := range request {
-		op := ovsdb.Operation{Op: ovsdb.OperationSelect, Table: t, Columns: request.Columns}
+	for t, request := range m.request {
+		// the conditions might refer to columns that were not requested, so
+		// select all of them and filter them out afterwards
+		op := ovsdb.Operation{Op: ovsdb.OperationSelect, Table: t}
 		result, _ := transaction.Transact(op)
 		if len(result) == 0 || len(result[0].Rows) == 0 {
 			continue
 		}
+		cols := map[string]bool{"_uuid": true}
+		for _, c := range request.Columns {
+			cols[c] = true
+		}
 		rows := result[0].Rows
-		tableUpdates[t] = make(ovsdb.TableUpdate2, len(rows))
 		for i := range rows {
+			if !m.matches(t, &rows[i]) {
+				continue
+			}
+			row := &rows[i]
+			if len(request.Columns) > 0 {
+				row = filterColumns(row, cols)
+			}
+			if _, ok := tableUpdates[t]; !ok {
+				tableUpdates[t] = make(ovsdb.TableUpdate2)
+			}
 			uuid := rows[i]["_uuid"].(ovsdb.UUID).GoUUID
-			tableUpdates[t][uuid] = &ovsdb.RowUpdate2{Initial: &rows[i]}
+			tableUpdates[t][uuid] = &ovsdb.RowUpdate2{Initial: row}
 		}
 	}
-	*reply = ovsdb.MonitorCondSinceReply{Found: false, LastTransactionID: "00000000-0000-0000-000000000000", Updates: tableUpdates}
-	o.monitors[client].monitors[value] = newConditionalSinceMonitor(value, request, client)
+	return tableUpdates
+}
+
+// MonitorCondChange changes the conditions of an existing conditional monitor,
+// and sends the client the rows that start or stop matching them
+func (o *OvsdbServer) MonitorCondChange(client *rpc2.Client, args []json.RawMessage, reply *struct{}) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expected 3 args, got %d", len(args))
+	}
+	value := string(args[0])
+	newValue := string(args[1])
+	var requests map[string][]ovsdb.MonitorCondChangeRequest
+	if err := json.Unmarshal(args[2], &requests); err != nil {
+		return err
+	}
+	flushed, err := o.changeMonitorConditions(client, value, newValue, requests)
+	if err != nil {
+		return err
+	}
+	// the updates resulting from the change are sent before the reply
+	<-flushed
+	*reply = struct{}{}
 	return nil
 }
 
-// MonitorCancel cancels a monitor on a given table
-func (o *OvsdbServer) MonitorCancel(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
-	return fmt.Errorf("not implemented")
+// changeMonitorConditions changes the conditions of a monitor, queues the
+// resulting updates and returns a channel that is closed once they are sent
+func (o *OvsdbServer) changeMonitorConditions(client *rpc2.Client, value, newValue string, requests map[string][]ovsdb.MonitorCondChangeRequest) (<-chan struct{}, error) {
+	// transactions must not be processed while the conditions change so
+	// that the client neither misses nor gets duplicated updates
+	db, err := o.monitorDatabase(client, value)
+	if err != nil {
+		return nil, err
+	}
+	o.lockTransactions(db)
+	defer o.unlockTransactions(db)
+	o.monitorMutex.Lock()
+	defer o.monitorMutex.Unlock()
+	clientMonitors, ok := o.monitors[client]
+	if !ok {
+		return nil, fmt.Errorf("unknown monitor")
+	}
+	m, ok := clientMonitors.monitors[value]
+	if !ok || m.schema.Name != db {
+		return nil, fmt.Errorf("unknown monitor")
+	}
+	if m.kind == monitorKindOriginal {
+		return nil, fmt.Errorf("monitor does not support conditions")
+	}
+	if _, ok := clientMonitors.monitors[newValue]; ok && newValue != value {
+		return nil, fmt.Errorf("monitor with that value already exists")
+	}
+
+	request := make(map[string]*ovsdb.MonitorRequest, len(m.request))
+	for table, r := range m.request {
+		request[table] = r
+	}
+	for table, changes := range requests {
+		r, ok := m.request[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s is not monitored", table)
+		}
+		for _, change := range changes {
+			changed := *r
+			changed.Where = change.Where
+			request[table] = &changed
+		}
+	}
+	changed := &monitor{
+		id:      newValue,
+		kind:    m.kind,
+		request: request,
+		schema:  m.schema,
+		client:  client,
+	}
+	if err := changed.checkConditions(); err != nil {
+		return nil, err
+	}
+
+	tableUpdates := o.conditionChangeUpdates2(m, changed)
+	m.id = changed.id
+	m.request = changed.request
+	delete(clientMonitors.monitors, value)
+	clientMonitors.monitors[newValue] = m
+	m.SendUpdates2(tableUpdates)
+	return clientMonitors.flush(), nil
 }
 
-// Lock acquires a lock on a table for a the client
-func (o *OvsdbServer) Lock(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
-	return fmt.Errorf("not implemented")
+// monitorDatabase returns the database of a monitor of the client
+func (o *OvsdbServer) monitorDatabase(client *rpc2.Client, value string) (string, error) {
+	o.monitorMutex.RLock()
+	defer o.monitorMutex.RUnlock()
+	clientMonitors, ok := o.monitors[client]
+	if !ok {
+		return "", fmt.Errorf("unknown monitor")
+	}
+	m, ok := clientMonitors.monitors[value]
+	if !ok {
+		return "", fmt.Errorf("unknown monitor")
+	}
+	return m.schema.Name, nil
+}
+
+// conditionChangeUpdates2 returns the updates that bring a client from the
+// rows matching the conditions of a monitor to the rows matching the
+// conditions of the changed monitor
+func (o *OvsdbServer) conditionChangeUpdates2(m, changed *monitor) ovsdb.TableUpdates2 {
+	transaction := o.db.NewTransaction(m.schema.Name)
+
+	tableUpdates := make(ovsdb.TableUpdates2)
+	for t, request := range changed.request {
+		op := ovsdb.Operation{Op: ovsdb.OperationSelect, Table: t}
+		result, _ := transaction.Transact(op)
+		if len(result) == 0 || len(result[0].Rows) == 0 {
+			continue
+		}
+		cols := map[string]bool{"_uuid": true}
+		for _, c := range request.Columns {
+			cols[c] = true
+		}
+		rows := result[0].Rows
+		for i := range rows {
+			var update *ovsdb.RowUpdate2
+			oldMatches := m.matches(t, &rows[i])
+			newMatches := changed.matches(t, &rows[i])
+			switch {
+			case newMatches && !oldMatches && request.Select.Insert():
+				row := &rows[i]
+				if len(request.Columns) > 0 {
+					row = filterColumns(row, cols)
+				}
+				update = &ovsdb.RowUpdate2{Insert: row}
+			case oldMatches && !newMatches && request.Select.Delete():
+				update = &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}}
+			default:
+				continue
+			}
+			if _, ok := tableUpdates[t]; !ok {
+				tableUpdates[t] = make(ovsdb.TableUpdate2)
+			}
+			uuid := rows[i]["_uuid"].(ovsdb.UUID).GoUUID
+			tableUpdates[t][uuid] = update
+		}
+	}
+	return tableUpdates
+}
+
+// MonitorCancel cancels a monitor so that the client stops receiving its
+// updates
+func (o *OvsdbServer) MonitorCancel(client *rpc2.Client, args []json.RawMessage, reply *struct{}) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected 1 arg, got %d", len(args))
+	}
+	value := string(args[0])
+	o.monitorMutex.Lock()
+	defer o.monitorMutex.Unlock()
+	clientMonitors, ok := o.monitors[client]
+	if !ok {
+		return fmt.Errorf("unknown monitor")
+	}
+	if _, ok := clientMonitors.monitors[value]; !ok {
+		return fmt.Errorf("unknown monitor")
+	}
+	// the update notifications of the client keep being queued for the
+	// same sender until it disconnects, so that they are sent in order
+	delete(clientMonitors.monitors, value)
+	*reply = struct{}{}
+	return nil
+}
+
+// releaseClient releases the monitors and locks of a client that
+// disconnected
+func (o *OvsdbServer) releaseClient(client *rpc2.Client) {
+	o.monitorMutex.Lock()
+	if clientMonitors, ok := o.monitors[client]; ok {
+		clientMonitors.release()
+		delete(o.monitors, client)
+	}
+	o.monitorMutex.Unlock()
+	o.notifyLocks(o.locks.unlockAll(client))
+}
+
+// Lock acquires a lock for the client, or queues the client for it if the
+// lock is owned by another client
+func (o *OvsdbServer) Lock(client *rpc2.Client, args []interface{}, reply *ovsdb.LockResult) error {
+	id, err := lockID(args)
+	if err != nil {
+		return err
+	}
+	locked, err := o.locks.lock(client, id)
+	if err != nil {
+		return err
+	}
+	*reply = ovsdb.LockResult{Locked: locked}
+	return nil
 }
 
 // Steal steals a lock for a client
-func (o *OvsdbServer) Steal(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
-	return fmt.Errorf("not implemented")
+func (o *OvsdbServer) Steal(client *rpc2.Client, args []interface{}, reply *ovsdb.LockResult) error {
+	id, err := lockID(args)
+	if err != nil {
+		return err
+	}
+	notifications, err := o.locks.steal(client, id)
+	if err != nil {
+		return err
+	}
+	*reply = ovsdb.LockResult{Locked: true}
+	o.notifyLocks(notifications)
+	return nil
 }
 
 // Unlock releases a lock for a client
-func (o *OvsdbServer) Unlock(client *rpc2.Client, args []interface{}, reply *[]interface{}) error {
-	return fmt.Errorf("not implemented")
+func (o *OvsdbServer) Unlock(client *rpc2.Client, args []interface{}, reply *struct{}) error {
+	id, err := lockID(args)
+	if err != nil {
+		return err
+	}
+	notifications, err := o.locks.unlock(client, id)
+	if err != nil {
+		return err
+	}
+	*reply = struct{}{}
+	o.notifyLocks(notifications)
+	return nil
+}
+
+func lockID(args []interface{}) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a single lock id")
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("lock id %v is not a string", args[0])
+	}
+	return id, nil
+}
+
+// notifyLocks sends the locked and stolen notifications that result from
+// changes in the ownership of locks
+func (o *OvsdbServer) notifyLocks(notifications []lockNotification) {
+	for _, n := range notifications {
+		err := n.client.Notify(n.method, []interface{}{n.id})
+		if err != nil {
+			o.logger.V(5).Info("failed to send lock notification", "method", n.method, "lock", n.id, "error", err.Error())
+		}
+	}
 }
 
 // Echo tests the liveness of the connection
@@ -398,10 +876,15 @@ func (o *OvsdbServer) Echo(client *rpc2.Client, args []interface{}, reply *[]int
 	return nil
 }
 
-func (o *OvsdbServer) processMonitors(id uuid.UUID, update database.Update) {
+// processMonitors queues the update notifications of a transaction committed
+// to a database for the monitors of that database
+func (o *OvsdbServer) processMonitors(db string, id uuid.UUID, update database.Update) {
 	o.monitorMutex.RLock()
 	for _, c := range o.monitors {
 		for _, m := range c.monitors {
+			if m.schema.Name != db {
+				continue
+			}
 			switch m.kind {
 			case monitorKindOriginal:
 				m.Send(update)
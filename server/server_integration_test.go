@@ -2,20 +2,28 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
 	"github.com/google/uuid"
 	"github.com/ovn-org/libovsdb/cache"
 	"github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/libovsdb/database/inmemory"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/ovsdb/serverdb"
 	"github.com/ovn-org/libovsdb/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,7 +31,7 @@ import (
 	. "github.com/ovn-org/libovsdb/test"
 )
 
-func buildTestServerAndClient(t *testing.T) (client.Client, func()) {
+func buildTestServer(t *testing.T) (*OvsdbServer, string) {
 	dbModel, err := GetModel()
 	require.NoError(t, err)
 	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
@@ -32,26 +40,39 @@ func buildTestServerAndClient(t *testing.T) (client.Client, func()) {
 
 	rand.Seed(time.Now().UnixNano())
 	tmpfile := fmt.Sprintf("/tmp/ovsdb-%d.sock", rand.Intn(10000))
-	defer os.Remove(tmpfile)
+	t.Cleanup(func() { os.Remove(tmpfile) })
 	dbModel, errs := model.NewDatabaseModel(schema, defDB)
 	require.Empty(t, errs)
 	server, err := NewOvsdbServer(ovsDB, dbModel)
 	assert.Nil(t, err)
+	t.Cleanup(server.Close)
 
 	go func(t *testing.T, o *OvsdbServer) {
 		if err := o.Serve("unix", tmpfile); err != nil {
 			t.Error(err)
 		}
 	}(t, server)
-	defer server.Close()
 	require.Eventually(t, func() bool {
 		return server.Ready()
 	}, 1*time.Second, 10*time.Millisecond)
 
-	ovs, err := client.NewOVSDBClient(defDB, client.WithEndpoint(fmt.Sprintf("unix:%s", tmpfile)))
+	return server, fmt.Sprintf("unix:%s", tmpfile)
+}
+
+func buildTestClient(t *testing.T, endpoint string, opts ...client.Option) client.Client {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	opts = append(opts, client.WithEndpoint(endpoint))
+	ovs, err := client.NewOVSDBClient(dbModel.Client(), opts...)
 	require.NoError(t, err)
 	err = ovs.Connect(context.Background())
 	require.NoError(t, err)
+	return ovs
+}
+
+func buildTestServerAndClient(t *testing.T) (client.Client, func()) {
+	server, endpoint := buildTestServer(t)
+	ovs := buildTestClient(t, endpoint)
 
 	return ovs, func() {
 		ovs.Disconnect()
@@ -862,3 +883,821 @@ func TestReferentialIntegrity(t *testing.T) {
 		})
 	}
 }
+
+func TestClientServerMonitorConditions(t *testing.T) {
+	ovs, close := buildTestServerAndClient(t)
+	defer close()
+
+	foo := &BridgeType{Name: "foo", DatapathType: "monitored"}
+	bar := &BridgeType{Name: "bar", DatapathType: "ignored"}
+	ops, err := ovs.Create(foo, bar)
+	require.NoError(t, err)
+	reply, err := ovs.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	foo.UUID = reply[0].UUID.GoUUID
+	bar.UUID = reply[1].UUID.GoUUID
+
+	bridge := &BridgeType{}
+	_, err = ovs.Monitor(context.Background(),
+		ovs.NewMonitor(
+			client.WithConditionalTable(bridge, []model.Condition{
+				{
+					Field:    &bridge.DatapathType,
+					Function: ovsdb.ConditionEqual,
+					Value:    "monitored",
+				},
+			}),
+		),
+	)
+	require.NoError(t, err)
+
+	bridges := func() []string {
+		names := []string{}
+		for _, row := range ovs.Cache().Table("Bridge").Rows() {
+			names = append(names, row.(*BridgeType).Name)
+		}
+		return names
+	}
+	update := func(br *BridgeType, datapathType string) {
+		br.DatapathType = datapathType
+		ops, err := ovs.Where(br).Update(br, &br.DatapathType)
+		require.NoError(t, err)
+		reply, err := ovs.Transact(context.Background(), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+
+	// only the rows matching the conditions are initially received
+	assert.Equal(t, []string{"foo"}, bridges())
+
+	// rows that start matching the conditions are inserted
+	update(bar, "monitored")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar", "foo"}, sorted(bridges()))
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// rows that stop matching the conditions are deleted
+	update(foo, "ignored")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar"}, bridges())
+	}, 1*time.Second, 10*time.Millisecond)
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func TestClientServerMonitorCondChange(t *testing.T) {
+	ovs, close := buildTestServerAndClient(t)
+	defer close()
+
+	foo := &BridgeType{Name: "foo", DatapathType: "system"}
+	bar := &BridgeType{Name: "bar", DatapathType: "netdev"}
+	ops, err := ovs.Create(foo, bar)
+	require.NoError(t, err)
+	reply, err := ovs.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+
+	bridge := &BridgeType{}
+	condition := func(datapathType string) []model.Condition {
+		return []model.Condition{
+			{
+				Field:    &bridge.DatapathType,
+				Function: ovsdb.ConditionEqual,
+				Value:    datapathType,
+			},
+		}
+	}
+	bridges := func() []string {
+		names := []string{}
+		for _, row := range ovs.Cache().Table("Bridge").Rows() {
+			names = append(names, row.(*BridgeType).Name)
+		}
+		return sorted(names)
+	}
+
+	cookie, err := ovs.Monitor(context.Background(),
+		ovs.NewMonitor(
+			client.WithConditionalTable(bridge, condition("system"), &bridge.Name, &bridge.DatapathType),
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, bridges())
+
+	// the cache is updated with the rows matching the new conditions
+	err = ovs.MonitorCondChange(context.Background(), cookie, client.WithConditionalTable(bridge, condition("netdev")))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar"}, bridges())
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// later updates are filtered according to the new conditions
+	baz := &BridgeType{Name: "baz", DatapathType: "netdev"}
+	qux := &BridgeType{Name: "qux", DatapathType: "system"}
+	ops, err = ovs.Create(baz, qux)
+	require.NoError(t, err)
+	reply, err = ovs.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar", "baz"}, bridges())
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// no conditions match all rows
+	err = ovs.MonitorCondChange(context.Background(), cookie, client.WithTable(bridge))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar", "baz", "foo", "qux"}, bridges())
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// only monitored tables can be changed
+	err = ovs.MonitorCondChange(context.Background(), cookie, client.WithTable(&OvsType{}))
+	assert.Error(t, err)
+	err = ovs.MonitorCondChange(context.Background(), client.MonitorCookie{DatabaseName: "Open_vSwitch", ID: "unknown"}, client.WithTable(bridge))
+	assert.Error(t, err)
+}
+
+func TestClientServerMonitorCondSinceReconnect(t *testing.T) {
+	_, endpoint := buildTestServer(t)
+	ovs := buildTestClient(t, endpoint, client.WithReconnect(2*time.Second, &backoff.ZeroBackOff{}))
+	defer ovs.Close()
+	other := buildTestClient(t, endpoint)
+	defer other.Close()
+
+	_, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+
+	transact := func(ops []ovsdb.Operation, err error) []ovsdb.OperationResult {
+		require.NoError(t, err)
+		reply, err := other.Transact(context.Background(), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+		return reply
+	}
+	bridges := func() []string {
+		names := []string{}
+		for _, row := range ovs.Cache().Table("Bridge").Rows() {
+			names = append(names, row.(*BridgeType).Name)
+		}
+		return sorted(names)
+	}
+
+	foo := &BridgeType{Name: "foo"}
+	bar := &BridgeType{Name: "bar"}
+	reply := transact(other.Create(foo, bar))
+	foo.UUID = reply[0].UUID.GoUUID
+	bar.UUID = reply[1].UUID.GoUUID
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"bar", "foo"}, bridges())
+	}, 1*time.Second, 10*time.Millisecond)
+
+	var mutex sync.Mutex
+	added := []string{}
+	ovs.Cache().AddEventHandler(&cache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			if br, ok := m.(*BridgeType); ok {
+				mutex.Lock()
+				added = append(added, br.Name)
+				mutex.Unlock()
+			}
+		},
+	})
+
+	// the cache is kept on reconnection and only receives the changes it
+	// missed instead of all the rows again
+	ovs.Disconnect()
+	foo.DatapathType = "netdev"
+	transact(other.Where(foo).Update(foo, &foo.DatapathType))
+	transact(other.Where(bar).Delete())
+	transact(other.Create(&BridgeType{Name: "baz"}))
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"baz", "foo"}, bridges())
+	}, 2*time.Second, 10*time.Millisecond)
+	br := &BridgeType{UUID: foo.UUID}
+	err = ovs.Get(context.Background(), br)
+	require.NoError(t, err)
+	assert.Equal(t, "netdev", br.DatapathType)
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return assert.ObjectsAreEqual([]string{"baz"}, added)
+	}, 1*time.Second, 10*time.Millisecond)
+}
+
+func TestClientServerMonitorCancel(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Close()
+
+	cookie, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+	monitors := func() int {
+		server.monitorMutex.RLock()
+		defer server.monitorMutex.RUnlock()
+		n := 0
+		for _, clientMonitors := range server.monitors {
+			n += len(clientMonitors.monitors)
+		}
+		return n
+	}
+	require.Equal(t, 1, monitors())
+
+	// the cache is no longer updated once the monitor is canceled
+	err = ovs.MonitorCancel(context.Background(), cookie)
+	require.NoError(t, err)
+	assert.Equal(t, 0, monitors())
+	ops, err := ovs.Create(&BridgeType{Name: "foo"})
+	require.NoError(t, err)
+	reply, err := ovs.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	assert.Never(t, func() bool {
+		return len(ovs.Cache().Table("Bridge").Rows()) > 0
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	err = ovs.MonitorCancel(context.Background(), cookie)
+	assert.EqualError(t, err, "unknown monitor")
+
+	// the monitors of a client are released when it disconnects
+	_, err = ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, monitors())
+	ovs.Close()
+	assert.Eventually(t, func() bool {
+		return monitors() == 0
+	}, 1*time.Second, 10*time.Millisecond)
+}
+
+func TestClientServerTransaction(t *testing.T) {
+	ovs, close := buildTestServerAndClient(t)
+	defer close()
+
+	_, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+
+	// models refer to the ones inserted in the same transaction by their
+	// named UUIDs, that are replaced on commit
+	port := &PortType{Name: "port"}
+	bridge := &BridgeType{Name: "foo"}
+	txn := ovs.NewTransaction()
+	err = txn.Create(port)
+	require.NoError(t, err)
+	require.True(t, ovsdb.IsNamedUUID(port.UUID))
+	bridge.Ports = []string{port.UUID}
+	err = txn.Create(bridge)
+	require.NoError(t, err)
+	ovsRow := &OvsType{Bridges: []string{bridge.UUID}}
+	err = txn.Create(ovsRow)
+	require.NoError(t, err)
+	require.Len(t, txn.Operations(), 3)
+	errs, err := txn.Commit(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	assert.True(t, ovsdb.IsValidUUID(port.UUID))
+	assert.True(t, ovsdb.IsValidUUID(bridge.UUID))
+	assert.True(t, ovsdb.IsValidUUID(ovsRow.UUID))
+	assert.Equal(t, []string{port.UUID}, bridge.Ports)
+	assert.Equal(t, []string{bridge.UUID}, ovsRow.Bridges)
+	require.Eventually(t, func() bool {
+		br := &BridgeType{UUID: bridge.UUID}
+		err := ovs.Get(context.Background(), br)
+		return err == nil && assert.ObjectsAreEqual(bridge.Ports, br.Ports)
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// the errors of the failed operations point at the models they were
+	// added for
+	txn = ovs.NewTransaction()
+	bridge.ExternalIds = map[string]string{"foo": "bar"}
+	err = txn.Where(bridge).Update(bridge, &bridge.ExternalIds)
+	require.NoError(t, err)
+	collector := &FlowSampleCollectorSetType{Bridge: bridge.UUID, ID: -1}
+	err = txn.Create(collector)
+	require.NoError(t, err)
+	errs, err = txn.Commit(context.Background())
+	require.Error(t, err)
+	require.Len(t, errs, 1)
+	assert.Same(t, collector, errs[0].Model)
+	assert.Equal(t, ovsdb.OperationInsert, errs[0].Operation().Op)
+	var constraintViolation *ovsdb.ConstraintViolation
+	assert.ErrorAs(t, errs[0], &constraintViolation)
+	assert.True(t, ovsdb.IsNamedUUID(collector.UUID))
+}
+
+func TestClientServerTransactWaitForCache(t *testing.T) {
+	ovs, close := buildTestServerAndClient(t)
+	defer close()
+
+	_, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+
+	transact := func(ops []ovsdb.Operation, err error) []ovsdb.OperationResult {
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		reply, err := ovs.Transact(client.WaitForCache(ctx), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+		return reply
+	}
+
+	// the cache reflects the transaction as soon as it returns
+	bridge := &BridgeType{Name: "foo"}
+	reply := transact(ovs.Create(bridge))
+	bridge.UUID = reply[0].UUID.GoUUID
+	br := &BridgeType{UUID: bridge.UUID}
+	err = ovs.Get(context.Background(), br)
+	require.NoError(t, err)
+
+	bridge.ExternalIds = map[string]string{"foo": "bar"}
+	transact(ovs.Where(bridge).Update(bridge, &bridge.ExternalIds))
+	err = ovs.Get(context.Background(), br)
+	require.NoError(t, err)
+	assert.Equal(t, bridge.ExternalIds, br.ExternalIds)
+
+	transact(ovs.Where(bridge).Mutate(bridge, model.Mutation{
+		Field:   &bridge.ExternalIds,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   map[string]string{"baz": "quux"},
+	}))
+	err = ovs.Get(context.Background(), br)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar", "baz": "quux"}, br.ExternalIds)
+
+	transact(ovs.Where(bridge).Delete())
+	err = ovs.Get(context.Background(), br)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func TestClientServerTransactWaitForCacheConcurrentWriter(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	defer server.Close()
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Disconnect()
+	writer := buildTestClient(t, endpoint)
+	defer writer.Disconnect()
+
+	_, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+	bridge := &BridgeType{Name: "foo"}
+	ops, err := ovs.Create(bridge)
+	require.NoError(t, err)
+	reply, err := ovs.Transact(client.WaitForCache(context.Background()), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	bridge.UUID = reply[0].UUID.GoUUID
+
+	// another client keeps changing the row, so the cache might never hold
+	// the values set by the transactions waiting for it
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			br := &BridgeType{UUID: bridge.UUID, ExternalIds: map[string]string{"writer": fmt.Sprint(i)}}
+			ops, err := writer.Where(br).Update(br, &br.ExternalIds)
+			if err != nil {
+				return
+			}
+			_, _ = writer.Transact(context.Background(), ops...)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		br := &BridgeType{UUID: bridge.UUID, ExternalIds: map[string]string{"ovs": fmt.Sprint(i)}}
+		ops, err := ovs.Where(br).Update(br, &br.ExternalIds)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		reply, err := ovs.Transact(client.WaitForCache(ctx), ops...)
+		cancel()
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+	close(stop)
+	<-done
+
+	// the wait is over once the row is deleted by another client
+	deleted := make(chan struct{})
+	go func() {
+		defer close(deleted)
+		ops, err := writer.Where(bridge).Delete()
+		if err == nil {
+			_, _ = writer.Transact(context.Background(), ops...)
+		}
+	}()
+	bridge.ExternalIds = map[string]string{"ovs": "last"}
+	ops, err = ovs.Where(bridge).Update(bridge, &bridge.ExternalIds)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = ovs.Transact(client.WaitForCache(ctx), ops...)
+	require.NoError(t, err)
+	<-deleted
+}
+
+func TestClientServerOptimisticTransact(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	defer server.Close()
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Disconnect()
+	other := buildTestClient(t, endpoint)
+	defer other.Disconnect()
+
+	_, err := ovs.MonitorAll(context.Background())
+	require.NoError(t, err)
+
+	bridge := &BridgeType{Name: "foo", ExternalIds: map[string]string{}}
+	ops, err := ovs.Create(bridge)
+	require.NoError(t, err)
+	reply, err := ovs.Transact(client.WaitForCache(context.Background()), ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+	bridge.UUID = reply[0].UUID.GoUUID
+
+	// changes the row read by the transaction from another client
+	conflict := func(key string) {
+		br := &BridgeType{UUID: bridge.UUID}
+		ops, err := other.Where(br).Mutate(br, model.Mutation{
+			Field:   &br.ExternalIds,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   map[string]string{key: "other"},
+		})
+		require.NoError(t, err)
+		reply, err := other.Transact(context.Background(), ops...)
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+
+	// the transaction is run again with the rows changed in between
+	runs := 0
+	errs, err := ovs.OptimisticTransact(context.Background(), nil, func(txn client.OptimisticTransaction) error {
+		runs++
+		br := &BridgeType{UUID: bridge.UUID}
+		if err := txn.Get(context.Background(), br); err != nil {
+			return err
+		}
+		if runs == 1 {
+			conflict("foo")
+		}
+		if br.ExternalIds == nil {
+			br.ExternalIds = map[string]string{}
+		}
+		br.ExternalIds["bar"] = "ovs"
+		return txn.Where(br).Update(br, &br.ExternalIds)
+	})
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	assert.Equal(t, 2, runs)
+	require.Eventually(t, func() bool {
+		br := &BridgeType{UUID: bridge.UUID}
+		err := ovs.Get(context.Background(), br)
+		return err == nil && assert.ObjectsAreEqual(map[string]string{"foo": "other", "bar": "ovs"}, br.ExternalIds)
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// the rows read through WhereCache are guarded as well, and the
+	// transaction is given up once the backoff stops
+	runs = 0
+	backOff := backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 2)
+	_, err = ovs.OptimisticTransact(context.Background(), backOff, func(txn client.OptimisticTransaction) error {
+		runs++
+		var bridges []BridgeType
+		err := txn.WhereCache(func(br *BridgeType) bool { return br.Name == "foo" }).List(context.Background(), &bridges)
+		if err != nil {
+			return err
+		}
+		require.Len(t, bridges, 1)
+		conflict(fmt.Sprintf("run%d", runs))
+		br := &bridges[0]
+		br.ExternalIds["baz"] = "ovs"
+		return txn.Where(br).Update(br, &br.ExternalIds)
+	})
+	assert.ErrorIs(t, err, client.ErrConflict)
+	assert.Equal(t, 3, runs)
+	br := &BridgeType{UUID: bridge.UUID}
+	err = ovs.Get(context.Background(), br)
+	require.NoError(t, err)
+	assert.NotContains(t, br.ExternalIds, "baz")
+}
+
+func TestClientServerTransactCancel(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	defer server.Close()
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Disconnect()
+
+	// waits, with no timeout, for a bridge that is never created
+	wait := ovsdb.Operation{
+		Op:      ovsdb.OperationWait,
+		Table:   "Bridge",
+		Where:   []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "foo")},
+		Columns: []string{"name"},
+		Until:   "==",
+		Rows:    []ovsdb.Row{{"name": "foo"}},
+	}
+
+	// the server aborts the transaction that the client gives up on, so that
+	// the next ones are processed
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_, err := ovs.Transact(ctx, wait)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ops, err := ovs.Create(&BridgeType{Name: "bar"})
+	require.NoError(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := ovs.Transact(ctx, ops...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, ops)
+	require.NoError(t, err)
+
+	// the canceled transaction fails with a "canceled" error
+	conn, err := net.Dial("unix", strings.TrimPrefix(endpoint, "unix:"))
+	require.NoError(t, err)
+	rpcClient := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+	go rpcClient.Run()
+	defer rpcClient.Close()
+	var result []ovsdb.OperationResult
+	call := rpcClient.Go("transact", ovsdb.NewTransactArgs("Open_vSwitch", wait), &result, make(chan *rpc2.Call, 1))
+	// the first request of an rpc2 client has id 1
+	err = rpcClient.Notify("cancel", ovsdb.NewCancelArgs(1))
+	require.NoError(t, err)
+	select {
+	case <-call.Done:
+		assert.EqualError(t, call.Error, "canceled")
+	case <-time.After(2 * time.Second):
+		t.Fatal("transaction was not canceled")
+	}
+}
+
+func TestClientServerTransactWait(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	defer server.Close()
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Disconnect()
+	other := buildTestClient(t, endpoint)
+	defer other.Disconnect()
+
+	wait := func(name string, timeout int) ovsdb.Operation {
+		return ovsdb.Operation{
+			Op:      ovsdb.OperationWait,
+			Table:   "Bridge",
+			Timeout: &timeout,
+			Where:   []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, name)},
+			Columns: []string{"name"},
+			Until:   "==",
+			Rows:    []ovsdb.Row{{"name": name}},
+		}
+	}
+
+	// a transaction waiting for a bridge does not hold up the one creating
+	// it, and is processed again once it is committed
+	ops := []ovsdb.Operation{wait("foo", 5000)}
+	more, err := ovs.Create(&BridgeType{Name: "bar"})
+	require.NoError(t, err)
+	ops = append(ops, more...)
+	done := make(chan []ovsdb.OperationResult, 1)
+	go func() {
+		reply, err := ovs.Transact(context.Background(), ops...)
+		assert.NoError(t, err)
+		done <- reply
+	}()
+	select {
+	case <-done:
+		t.Fatal("transaction did not wait")
+	case <-time.After(300 * time.Millisecond):
+	}
+	create, err := other.Create(&BridgeType{Name: "foo"})
+	require.NoError(t, err)
+	reply, err := other.Transact(context.Background(), create...)
+	require.NoError(t, err)
+	_, err = ovsdb.CheckOperationResults(reply, create)
+	require.NoError(t, err)
+	select {
+	case reply := <-done:
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("transaction was not processed again")
+	}
+
+	// the wait times out unless a commit satisfies it
+	ops = []ovsdb.Operation{wait("baz", 500)}
+	go func() {
+		reply, err := ovs.Transact(context.Background(), ops...)
+		assert.NoError(t, err)
+		done <- reply
+	}()
+	create, err = other.Create(&BridgeType{Name: "qux"})
+	require.NoError(t, err)
+	_, err = other.Transact(context.Background(), create...)
+	require.NoError(t, err)
+	select {
+	case reply := <-done:
+		require.Len(t, reply, 1)
+		assert.Equal(t, "timed out", reply[0].Error)
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait did not time out")
+	}
+}
+
+func TestClientServerSlowMonitor(t *testing.T) {
+	server, endpoint := buildTestServer(t)
+	defer server.Close()
+	const queueSize = 5
+	server.SetUpdateQueueSize(queueSize)
+	ovs := buildTestClient(t, endpoint)
+	defer ovs.Disconnect()
+
+	monitor := func(handler func(*rpc2.Client, []json.RawMessage, *[]interface{}) error) *rpc2.Client {
+		conn, err := net.Dial("unix", strings.TrimPrefix(endpoint, "unix:"))
+		require.NoError(t, err)
+		c := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+		c.Handle("update2", handler)
+		go c.Run()
+		t.Cleanup(func() { c.Close() })
+		var updates ovsdb.TableUpdates2
+		err = c.Call("monitor_cond", ovsdb.NewMonitorArgs("Open_vSwitch", "monitor", map[string]ovsdb.MonitorRequest{"Bridge": {Columns: []string{"name"}, Select: ovsdb.NewDefaultMonitorSelect()}}), &updates)
+		require.NoError(t, err)
+		return c
+	}
+
+	// a monitoring client that does not handle its updates
+	unblock := make(chan struct{})
+	defer close(unblock)
+	slow := monitor(func(_ *rpc2.Client, _ []json.RawMessage, reply *[]interface{}) error {
+		<-unblock
+		*reply = []interface{}{}
+		return nil
+	})
+
+	// a monitoring client that handles its updates
+	var mutex sync.Mutex
+	names := []string{}
+	monitor(func(_ *rpc2.Client, args []json.RawMessage, reply *[]interface{}) error {
+		var updates ovsdb.TableUpdates2
+		if err := json.Unmarshal(args[1], &updates); err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, update := range updates["Bridge"] {
+			names = append(names, (*update.Insert)["name"].(string))
+		}
+		*reply = []interface{}{}
+		return nil
+	})
+
+	// the slow client does not hold up the transactions
+	expected := []string{}
+	for i := 0; i < 2*queueSize; i++ {
+		name := fmt.Sprintf("br%d", i)
+		expected = append(expected, name)
+		ops, err := ovs.Create(&BridgeType{Name: name})
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		reply, err := ovs.Transact(ctx, ops...)
+		cancel()
+		require.NoError(t, err)
+		_, err = ovsdb.CheckOperationResults(reply, ops)
+		require.NoError(t, err)
+	}
+
+	// and is disconnected once its queue is full
+	select {
+	case <-slow.DisconnectNotify():
+	case <-time.After(2 * time.Second):
+		t.Fatal("the slow client was not disconnected")
+	}
+
+	// while the other client gets the updates in order
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(names) == len(expected)
+	}, 2*time.Second, 10*time.Millisecond)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, expected, names)
+}
+
+func TestClientServerLeaderOnly(t *testing.T) {
+	server1, endpoint1 := buildTestServer(t)
+	server2, endpoint2 := buildTestServer(t)
+
+	// the _Server database reports the databases of the server
+	serverDBModel, err := serverdb.FullDatabaseModel()
+	require.NoError(t, err)
+	serverClient, err := client.NewOVSDBClient(serverDBModel, client.WithEndpoint(endpoint1))
+	require.NoError(t, err)
+	err = serverClient.Connect(context.Background())
+	require.NoError(t, err)
+	defer serverClient.Disconnect()
+	_, err = serverClient.MonitorAll(context.Background())
+	require.NoError(t, err)
+	databases := []serverdb.Database{}
+	err = serverClient.List(context.Background(), &databases)
+	require.NoError(t, err)
+	require.Len(t, databases, 2)
+	// clients cannot change the _Server database
+	ops, err := serverClient.Create(&serverdb.Database{Name: "fake", Model: serverdb.DatabaseModelClustered, Leader: true})
+	require.NoError(t, err)
+	_, err = serverClient.Transact(context.Background(), ops...)
+	assert.EqualError(t, err, "database _Server is read-only")
+	ops = []ovsdb.Operation{{
+		Op:    ovsdb.OperationUpdate,
+		Table: serverdb.DatabaseTable,
+		Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "Open_vSwitch")},
+		Row:   ovsdb.Row{"leader": false},
+	}}
+	_, err = serverClient.Transact(context.Background(), ops...)
+	assert.EqualError(t, err, "database _Server is read-only")
+	for _, db := range databases {
+		assert.Equal(t, serverdb.DatabaseModelStandalone, db.Model)
+		assert.True(t, db.Connected)
+		assert.True(t, db.Leader)
+		require.NotNil(t, db.Schema)
+		schema := server1.schema(db.Name)
+		expected, err := json.Marshal(schema)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), *db.Schema)
+	}
+
+	err = server1.SetLeader("Open_vSwitch", false)
+	require.NoError(t, err)
+	err = server2.SetLeader("Open_vSwitch", true)
+	require.NoError(t, err)
+	err = server1.SetLeader("Unknown", true)
+	assert.Error(t, err)
+	require.Eventually(t, func() bool {
+		databases := []serverdb.Database{}
+		err := serverClient.WhereCache(func(db *serverdb.Database) bool {
+			return db.Name == "Open_vSwitch"
+		}).List(context.Background(), &databases)
+		if err != nil || len(databases) != 1 {
+			return false
+		}
+		db := databases[0]
+		return db.Model == serverdb.DatabaseModelClustered && !db.Leader && db.Sid != nil && *db.Sid == server1.ServerID() && db.Cid != nil && db.Index != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the servers report the same cluster, and the index follows the
+	// transactions committed to the database
+	status := func(server *OvsdbServer) *serverdb.Database {
+		rows, err := server.db.List(serverDatabaseName, serverdb.DatabaseTable, ovsdb.NewCondition("name", ovsdb.ConditionEqual, "Open_vSwitch"))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		for _, row := range rows {
+			return row.(*serverdb.Database)
+		}
+		return nil
+	}
+	status1, status2 := status(server1), status(server2)
+	require.NotNil(t, status1.Cid)
+	require.NotNil(t, status2.Cid)
+	assert.Equal(t, *status1.Cid, *status2.Cid)
+	assert.NotEqual(t, *status1.Sid, *status2.Sid)
+	index := *status1.Index
+	writer := buildTestClient(t, endpoint1)
+	defer writer.Disconnect()
+	ops, err = writer.Create(&BridgeType{Name: "foo"})
+	require.NoError(t, err)
+	_, err = writer.Transact(context.Background(), ops...)
+	require.NoError(t, err)
+	assert.Equal(t, index+1, *status(server1).Index)
+	assert.Equal(t, *status2.Index, *status(server2).Index)
+
+	// a leader-only client skips the follower, and reconnects to the new
+	// leader when leadership changes
+	ovs := buildTestClient(t, endpoint2,
+		client.WithEndpoint(endpoint1),
+		client.WithLeaderOnly(true),
+		client.WithReconnect(2*time.Second, &backoff.ZeroBackOff{}))
+	defer ovs.Close()
+	assert.Equal(t, endpoint2, ovs.CurrentEndpoint())
+
+	err = server1.SetLeader("Open_vSwitch", true)
+	require.NoError(t, err)
+	err = server2.SetLeader("Open_vSwitch", false)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return ovs.CurrentEndpoint() == endpoint1
+	}, 5*time.Second, 50*time.Millisecond)
+}
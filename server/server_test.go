@@ -1,9 +1,13 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/cenkalti/rpc2"
 	"github.com/google/uuid"
 	"github.com/ovn-org/libovsdb/database/inmemory"
 	"github.com/ovn-org/libovsdb/model"
@@ -110,3 +114,252 @@ func TestOvsdbServerMonitor(t *testing.T) {
 	}
 	assert.Equal(t, expected, reply)
 }
+
+func TestOvsdbServerMonitorCondSince(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+
+	o, err := NewOvsdbServer(ovsDB, dbModel)
+	require.Nil(t, err)
+
+	db, err := json.Marshal("Open_vSwitch")
+	require.Nil(t, err)
+	transact := func(operation ovsdb.Operation) {
+		op, err := json.Marshal(operation)
+		require.Nil(t, err)
+		reply := []*ovsdb.OperationResult{}
+		err = o.Transact(nil, []json.RawMessage{db, op}, &reply)
+		require.Nil(t, err)
+		require.Len(t, reply, 1)
+		require.Empty(t, reply[0].Error)
+	}
+	fooUUID := uuid.NewString()
+	barUUID := uuid.NewString()
+	bazUUID := uuid.NewString()
+
+	transact(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: fooUUID, Row: ovsdb.Row{"name": "foo"}})
+	first := o.history["Open_vSwitch"].lastTransactionID()
+	transact(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: barUUID, Row: ovsdb.Row{"name": "bar"}})
+	transact(ovsdb.Operation{
+		Op:    ovsdb.OperationUpdate,
+		Table: "Bridge",
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: fooUUID})},
+		Row:   ovsdb.Row{"datapath_type": "netdev"},
+	})
+	transact(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", UUID: bazUUID, Row: ovsdb.Row{"name": "baz"}})
+	transact(ovsdb.Operation{
+		Op:    ovsdb.OperationDelete,
+		Table: "Bridge",
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: bazUUID})},
+	})
+	last := o.history["Open_vSwitch"].lastTransactionID()
+
+	requests, err := json.Marshal(map[string]ovsdb.MonitorRequest{
+		"Bridge": {
+			Columns: []string{"name", "datapath_type"},
+			Select:  ovsdb.NewDefaultMonitorSelect(),
+		},
+	})
+	require.Nil(t, err)
+	monitorCondSince := func(value string, lastTransactionID string) ovsdb.MonitorCondSinceReply {
+		v, err := json.Marshal(value)
+		require.Nil(t, err)
+		id, err := json.Marshal(lastTransactionID)
+		require.Nil(t, err)
+		reply := ovsdb.MonitorCondSinceReply{}
+		err = o.MonitorCondSince(nil, []json.RawMessage{db, v, requests, id}, &reply)
+		require.Nil(t, err)
+		return reply
+	}
+
+	// a known transaction id gets only the changes since that transaction
+	reply := monitorCondSince("foo", first.String())
+	assert.True(t, reply.Found)
+	assert.Equal(t, last.String(), reply.LastTransactionID)
+	require.Len(t, reply.Updates["Bridge"], 2)
+	assert.Equal(t, &ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: barUUID}, "name": "bar"}, reply.Updates["Bridge"][barUUID].Insert)
+	assert.Equal(t, &ovsdb.Row{"datapath_type": "netdev"}, reply.Updates["Bridge"][fooUUID].Modify)
+
+	// the last transaction id gets no changes
+	reply = monitorCondSince("bar", last.String())
+	assert.True(t, reply.Found)
+	assert.Equal(t, last.String(), reply.LastTransactionID)
+	assert.Empty(t, reply.Updates["Bridge"])
+
+	// an unknown transaction id gets all the rows
+	reply = monitorCondSince("baz", uuid.NewString())
+	assert.False(t, reply.Found)
+	assert.Equal(t, last.String(), reply.LastTransactionID)
+	assert.Equal(t, ovsdb.TableUpdates2{
+		"Bridge": {
+			fooUUID: &ovsdb.RowUpdate2{
+				Initial: &ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: fooUUID}, "name": "foo", "datapath_type": "netdev"},
+			},
+			barUUID: &ovsdb.RowUpdate2{
+				Initial: &ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: barUUID}, "name": "bar"},
+			},
+		},
+	}, reply.Updates)
+}
+
+func TestOvsdbServerMonitorCancel(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+
+	o, err := NewOvsdbServer(ovsDB, dbModel)
+	require.Nil(t, err)
+
+	db, err := json.Marshal("Open_vSwitch")
+	require.Nil(t, err)
+	value, err := json.Marshal("foo")
+	require.Nil(t, err)
+	requests, err := json.Marshal(map[string]ovsdb.MonitorRequest{
+		"Bridge": {
+			Columns: []string{"name"},
+			Select:  ovsdb.NewDefaultMonitorSelect(),
+		},
+	})
+	require.Nil(t, err)
+	err = o.MonitorCond(nil, []json.RawMessage{db, value, requests}, &ovsdb.TableUpdates2{})
+	require.Nil(t, err)
+	require.Contains(t, o.monitors, (*rpc2.Client)(nil))
+
+	unknown, err := json.Marshal("bar")
+	require.Nil(t, err)
+	err = o.MonitorCancel(nil, []json.RawMessage{unknown}, &struct{}{})
+	assert.EqualError(t, err, "unknown monitor")
+
+	err = o.MonitorCancel(nil, []json.RawMessage{value}, &struct{}{})
+	require.Nil(t, err)
+	// the update queue of the client is kept until it disconnects
+	require.Contains(t, o.monitors, (*rpc2.Client)(nil))
+	assert.Empty(t, o.monitors[nil].monitors)
+
+	err = o.MonitorCancel(nil, []json.RawMessage{value}, &struct{}{})
+	assert.EqualError(t, err, "unknown monitor")
+
+	// a new monitor queues its updates along with the ones of the canceled
+	// monitor that may not have been sent yet
+	clientMonitors := o.monitors[nil]
+	err = o.MonitorCond(nil, []json.RawMessage{db, value, requests}, &ovsdb.TableUpdates2{})
+	require.Nil(t, err)
+	assert.Same(t, clientMonitors, o.monitors[nil])
+}
+
+func TestOvsdbServerTransactCanceled(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+
+	o, err := NewOvsdbServer(ovsDB, dbModel)
+	require.Nil(t, err)
+
+	db, err := json.Marshal("Open_vSwitch")
+	require.Nil(t, err)
+	insert := func(name string) []json.RawMessage {
+		op, err := json.Marshal(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": name}})
+		require.Nil(t, err)
+		return []json.RawMessage{db, op}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a transaction that does not have to wait is committed
+	reply := []*ovsdb.OperationResult{}
+	err = o.transactWithContext(ctx, nil, insert("foo"), &reply)
+	require.Nil(t, err)
+	require.Len(t, reply, 1)
+	assert.Empty(t, reply[0].Error)
+	assert.NotEqual(t, uuid.Nil, o.history["Open_vSwitch"].lastTransactionID())
+
+	// a transaction waiting for its turn is canceled
+	o.lockTransactions("Open_vSwitch")
+	reply = []*ovsdb.OperationResult{}
+	err = o.transactWithContext(ctx, nil, insert("bar"), &reply)
+	o.unlockTransactions("Open_vSwitch")
+	assert.IsType(t, &ovsdb.Canceled{}, err)
+	assert.Empty(t, reply)
+
+	rows, err := ovsDB.List("Open_vSwitch", "Bridge")
+	require.Nil(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestOvsdbServerTransactConcurrentDatabases(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	schema, err := GetSchema()
+	require.NoError(t, err)
+	schema.Name = "Other"
+	otherClientDBModel, err := model.NewClientDBModel("Other", map[string]model.Model{"Bridge": &BridgeType{}})
+	require.NoError(t, err)
+	otherDBModel, errs := model.NewDatabaseModel(schema, otherClientDBModel)
+	require.Empty(t, errs)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client(), "Other": otherClientDBModel})
+
+	o, err := NewOvsdbServer(ovsDB, dbModel, otherDBModel)
+	require.Nil(t, err)
+
+	transact := func(db string) <-chan error {
+		name, err := json.Marshal(db)
+		require.Nil(t, err)
+		op, err := json.Marshal(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "Bridge", Row: ovsdb.Row{"name": "foo"}})
+		require.Nil(t, err)
+		done := make(chan error, 1)
+		go func() {
+			reply := []*ovsdb.OperationResult{}
+			done <- o.Transact(nil, []json.RawMessage{name, op}, &reply)
+		}()
+		return done
+	}
+
+	// while a transaction is processed on a database
+	o.lockTransactions("Open_vSwitch")
+	blocked := transact("Open_vSwitch")
+
+	// the transactions on another database are committed
+	select {
+	case err := <-transact("Other"):
+		require.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the transaction on the other database was held up")
+	}
+	rows, err := ovsDB.List("Other", "Bridge")
+	require.Nil(t, err)
+	assert.Len(t, rows, 1)
+
+	// and the ones on the same database wait for their turn
+	select {
+	case <-blocked:
+		t.Fatal("the transaction on the same database was not serialized")
+	case <-time.After(100 * time.Millisecond):
+	}
+	o.unlockTransactions("Open_vSwitch")
+	select {
+	case err := <-blocked:
+		require.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the transaction on the same database was not processed")
+	}
+}
+
+func TestOvsdbServerCloseConcurrently(t *testing.T) {
+	dbModel, err := GetModel()
+	require.NoError(t, err)
+	ovsDB := inmemory.NewDatabase(map[string]model.ClientDBModel{"Open_vSwitch": dbModel.Client()})
+	o, err := NewOvsdbServer(ovsDB, dbModel)
+	require.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.Close()
+		}()
+	}
+	wg.Wait()
+	assert.False(t, o.Ready())
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/database"
+	"github.com/ovn-org/libovsdb/database/inmemory"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/ovsdb/serverdb"
+)
+
+// serverDatabaseName is the name of the database that reports the status of
+// the databases of the server
+const serverDatabaseName = "_Server"
+
+// serverDatabase is a database.Database that holds the _Server database
+// maintained by the server in its own in-memory database, and the other ones
+// in the database provided to the server
+type serverDatabase struct {
+	db     database.Database
+	server database.Database
+}
+
+func newServerDatabase(db database.Database, serverModel model.ClientDBModel) *serverDatabase {
+	return &serverDatabase{
+		db:     db,
+		server: inmemory.NewDatabase(map[string]model.ClientDBModel{serverDatabaseName: serverModel}),
+	}
+}
+
+func (d *serverDatabase) database(name string) database.Database {
+	if name == serverDatabaseName {
+		return d.server
+	}
+	return d.db
+}
+
+func (d *serverDatabase) CreateDatabase(name string, schema ovsdb.DatabaseSchema) error {
+	return d.database(name).CreateDatabase(name, schema)
+}
+
+func (d *serverDatabase) Exists(name string) bool {
+	return d.database(name).Exists(name)
+}
+
+func (d *serverDatabase) NewTransaction(name string, opts ...database.TransactionOption) database.Transaction {
+	return d.database(name).NewTransaction(name, opts...)
+}
+
+func (d *serverDatabase) Commit(name string, id uuid.UUID, update database.Update) error {
+	return d.database(name).Commit(name, id, update)
+}
+
+func (d *serverDatabase) CheckIndexes(name string, table string, m model.Model) error {
+	return d.database(name).CheckIndexes(name, table, m)
+}
+
+func (d *serverDatabase) List(name, table string, conditions ...ovsdb.Condition) (map[string]model.Model, error) {
+	return d.database(name).List(name, table, conditions...)
+}
+
+func (d *serverDatabase) Get(name, table string, uuid string) (model.Model, error) {
+	return d.database(name).Get(name, table, uuid)
+}
+
+func (d *serverDatabase) GetReferences(name, table, row string) (database.References, error) {
+	return d.database(name).GetReferences(name, table, row)
+}
+
+// serverDatabaseModel returns the model of the _Server database
+func serverDatabaseModel() (model.ClientDBModel, model.DatabaseModel, error) {
+	clientDBModel, err := serverdb.FullDatabaseModel()
+	if err != nil {
+		return model.ClientDBModel{}, model.DatabaseModel{}, err
+	}
+	dbModel, errs := model.NewDatabaseModel(serverdb.Schema(), clientDBModel)
+	if len(errs) > 0 {
+		return model.ClientDBModel{}, model.DatabaseModel{}, fmt.Errorf("failed to create the _Server database model: %v", errs)
+	}
+	return clientDBModel, dbModel, nil
+}
+
+// transactServerDatabase commits operations on the _Server database
+func (o *OvsdbServer) transactServerDatabase(ops ...ovsdb.Operation) error {
+	var reply []*ovsdb.OperationResult
+	_, err := o.processTransaction(context.Background(), nil, serverDatabaseName, ops, time.Now(), &reply)
+	if err != nil {
+		return err
+	}
+	for _, result := range reply {
+		if result.Error != "" {
+			return fmt.Errorf("failed to update the _Server database: %s: %s", result.Error, result.Details)
+		}
+	}
+	return nil
+}
+
+// addServerDatabaseRows reports the databases of the server as connected
+// standalone databases in the _Server database
+func (o *OvsdbServer) addServerDatabaseRows() error {
+	o.modelsMutex.RLock()
+	serverModel := o.models[serverDatabaseName]
+	var ops []ovsdb.Operation
+	for name, dbModel := range o.models {
+		schema, err := json.Marshal(dbModel.Schema)
+		if err != nil {
+			o.modelsMutex.RUnlock()
+			return err
+		}
+		s := string(schema)
+		row, err := serverModel.NewRow(&serverdb.Database{
+			Name:      name,
+			Model:     serverdb.DatabaseModelStandalone,
+			Connected: true,
+			Leader:    true,
+			Schema:    &s,
+		})
+		if err != nil {
+			o.modelsMutex.RUnlock()
+			return err
+		}
+		ops = append(ops, ovsdb.Operation{Op: ovsdb.OperationInsert, Table: serverdb.DatabaseTable, Row: row})
+	}
+	o.modelsMutex.RUnlock()
+	return o.transactServerDatabase(ops...)
+}
+
+// SetLeader reports in the _Server database that a database is clustered,
+// and whether the server is the leader of its cluster. Clients connected with
+// client.WithLeaderOnly reconnect to another server when it is not. The
+// cluster id is derived from the name of the database, so that the servers
+// of a test report the same cluster, and the index is kept up to date with
+// the transactions committed to the database. It fails if the _Server
+// database was provided to NewOvsdbServer rather than maintained by the
+// server.
+func (o *OvsdbServer) SetLeader(db string, leader bool) error {
+	if !o.maintainsServerDatabase {
+		return fmt.Errorf("the %s database is not maintained by the server", serverDatabaseName)
+	}
+	if db == serverDatabaseName {
+		return fmt.Errorf("the %s database cannot be clustered", serverDatabaseName)
+	}
+	o.modelsMutex.RLock()
+	serverModel := o.models[serverDatabaseName]
+	_, ok := o.models[db]
+	o.modelsMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("database %s does not exist", db)
+	}
+
+	// no transaction is committed to the database, which would update the
+	// index, in the meantime
+	o.lockTransactions(db)
+	defer o.unlockTransactions(db)
+	cid := clusterID(db).String()
+	sid := o.serverID.String()
+	index := o.history[db].committed
+	status := &serverdb.Database{
+		Model:  serverdb.DatabaseModelClustered,
+		Leader: leader,
+		Cid:    &cid,
+		Sid:    &sid,
+		Index:  &index,
+	}
+	row, err := serverModel.NewRow(status, &status.Model, &status.Leader, &status.Cid, &status.Sid, &status.Index)
+	if err != nil {
+		return err
+	}
+	err = o.transactServerDatabase(ovsdb.Operation{
+		Op:    ovsdb.OperationUpdate,
+		Table: serverdb.DatabaseTable,
+		Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, db)},
+		Row:   row,
+	})
+	if err != nil {
+		return err
+	}
+	o.clusteredMutex.Lock()
+	defer o.clusteredMutex.Unlock()
+	o.clustered[db] = true
+	return nil
+}
+
+// clusterID returns the id of the cluster of a database reported by SetLeader
+func clusterID(db string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(db))
+}
+
+// updateServerDatabaseIndex reports in the _Server database the number of
+// transactions committed to a database, if it is clustered. Caller must hold
+// the transaction lock of the database.
+func (o *OvsdbServer) updateServerDatabaseIndex(db string) error {
+	o.clusteredMutex.Lock()
+	clustered := o.clustered[db]
+	o.clusteredMutex.Unlock()
+	if !clustered {
+		return nil
+	}
+	o.modelsMutex.RLock()
+	serverModel := o.models[serverDatabaseName]
+	o.modelsMutex.RUnlock()
+	index := o.history[db].committed
+	status := &serverdb.Database{Index: &index}
+	row, err := serverModel.NewRow(status, &status.Index)
+	if err != nil {
+		return err
+	}
+	return o.transactServerDatabase(ovsdb.Operation{
+		Op:    ovsdb.OperationUpdate,
+		Table: serverdb.DatabaseTable,
+		Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, db)},
+		Row:   row,
+	})
+}
+
+// ServerID returns the id of the server reported for the clustered databases
+// in the _Server database, see SetLeader
+func (o *OvsdbServer) ServerID() string {
+	return o.serverID.String()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// waiter wakes up a transaction suspended by a wait operation that is neither
+// satisfied nor timed out, so that it is processed again, once a commit
+// changes the table waited on or the operation times out
+type waiter struct {
+	db     string
+	table  string
+	wakeup chan struct{}
+	once   sync.Once
+	timer  *time.Timer
+}
+
+func (w *waiter) wake() {
+	w.once.Do(func() { close(w.wakeup) })
+}
+
+// waiters holds the waiters of the suspended transactions
+type waiters struct {
+	waiters map[*waiter]struct{}
+	mutex   sync.Mutex
+}
+
+func newWaiters() *waiters {
+	return &waiters{
+		waiters: make(map[*waiter]struct{}),
+	}
+}
+
+// add returns a waiter woken up once a commit changes the table of the
+// database, or at the deadline unless it is zero
+func (w *waiters) add(db, table string, deadline time.Time) *waiter {
+	waiter := &waiter{db: db, table: table, wakeup: make(chan struct{})}
+	if !deadline.IsZero() {
+		waiter.timer = time.AfterFunc(time.Until(deadline), waiter.wake)
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.waiters[waiter] = struct{}{}
+	return waiter
+}
+
+// remove forgets a waiter once its transaction is processed again or
+// canceled
+func (w *waiters) remove(waiter *waiter) {
+	if waiter.timer != nil {
+		waiter.timer.Stop()
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.waiters, waiter)
+}
+
+// wake wakes up the waiters on the tables of the database changed by a
+// commit
+func (w *waiters) wake(db string, tables []string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for waiter := range w.waiters {
+		if waiter.db != db {
+			continue
+		}
+		for _, table := range tables {
+			if waiter.table == table {
+				waiter.wake()
+				delete(w.waiters, waiter)
+				break
+			}
+		}
+	}
+}
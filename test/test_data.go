@@ -1,6 +1,7 @@
 package test
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 
@@ -263,3 +264,11 @@ func GetSchema() (ovsdb.DatabaseSchema, error) {
 	err := json.Unmarshal([]byte(schema), &dbSchema)
 	return dbSchema, err
 }
+
+// DatabaseFileRecord returns a record of a database file holding the provided
+// JSON, as written by ovsdb-server: the length and the SHA-1 digest in its
+// header cover the JSON along with the new line that ends it
+func DatabaseFileRecord(data string) string {
+	data += "\n"
+	return fmt.Sprintf("OVSDB JSON %d %x\n%s", len(data), sha1.Sum([]byte(data)), data)
+}
@@ -21,6 +21,7 @@ type ReferenceProvider interface {
 type DatabaseUpdate struct {
 	ModelUpdates
 	referenceUpdates database.References
+	comment          string
 }
 
 func (u DatabaseUpdate) ForReferenceUpdates(do func(references database.References) error) error {
@@ -30,6 +31,16 @@ func (u DatabaseUpdate) ForReferenceUpdates(do func(references database.Referenc
 	return do(refsCopy)
 }
 
+func (u DatabaseUpdate) GetComment() string {
+	return u.comment
+}
+
+// WithComment returns a copy of the update that carries the provided comment
+func (u DatabaseUpdate) WithComment(comment string) DatabaseUpdate {
+	u.comment = comment
+	return u
+}
+
 func NewDatabaseUpdate(updates ModelUpdates, references database.References) DatabaseUpdate {
 	return DatabaseUpdate{
 		ModelUpdates:     updates,
@@ -48,6 +59,23 @@ func ProcessReferences(dbModel model.DatabaseModel, provider ReferenceProvider,
 	return referenceTracker.processReferences(updates)
 }
 
+// TrackReferences returns the references updated by the provided set of
+// updates. Unlike ProcessReferences, it neither collects garbage nor checks
+// referential integrity, so that updates that were already processed, like
+// the ones read from a database file, can be applied as they are.
+func TrackReferences(dbModel model.DatabaseModel, provider ReferenceProvider, updates ModelUpdates) (database.References, error) {
+	rt := newReferenceTracker(dbModel, provider)
+	rt.updates = updates
+	rt.tracked = make(map[string]string)
+	rt.added = make(map[string]string)
+	rt.deleted = make(map[string]string)
+	rt.references = make(database.References)
+	if err := rt.processModelUpdates(updates); err != nil {
+		return nil, err
+	}
+	return rt.references, nil
+}
+
 type referenceTracker struct {
 	dbModel  model.DatabaseModel
 	provider ReferenceProvider
@@ -58,7 +86,9 @@ type referenceTracker struct {
 	// references are the updated references by the set of updates processed
 	references database.References
 
-	// helper maps to track the rows that we are processing and their tables
+	// helper maps to track the rows that we are processing and their tables.
+	// added holds the rows inserted or modified that are not part of the root
+	// set.
 	tracked map[string]string
 	added   map[string]string
 	deleted map[string]string
@@ -167,6 +197,15 @@ func (rt *referenceTracker) processRowUpdate(table, uuid string, row *ovsdb.RowU
 		rt.deleted[uuid] = table
 		updateRefs = getReferenceModificationsFromRow(&rt.dbModel, table, uuid, row.Old, row.Old)
 	case row.Modify != nil:
+		if !isRoot(&rt.dbModel, table) {
+			// track rows modified that are not part of the root set, we might
+			// need to delete those later if they are not referenced
+			err := rt.initReferences(table, uuid)
+			if err != nil {
+				return err
+			}
+			rt.added[uuid] = table
+		}
 		updateRefs = getReferenceModificationsFromRow(&rt.dbModel, table, uuid, row.Modify, row.Old)
 	case row.Insert != nil:
 		if !isRoot(&rt.dbModel, table) {
@@ -238,8 +277,8 @@ func (rt *referenceTracker) processStrongReferences() (ModelUpdates, error) {
 		}
 	}
 
-	// inserted rows that are unreferenced and not part of the root set will
-	// silently be dropped from the updates
+	// inserted or modified rows that are unreferenced and not part of the
+	// root set will silently be dropped from the updates or deleted
 	for uuid := range rt.added {
 		if isReferenced[uuid] {
 			continue
@@ -466,6 +466,13 @@ func TestProcessReferences(t *testing.T) {
 			name:     "insert unreferenced row in non root set table is a noop",
 			testData: insertNoRootUnreferencedRowTestData(),
 		},
+		{
+			// corner case
+			// updating a row in a table that is not part of the root set and
+			// is not strongly referenced deletes it
+			name:     "update unreferenced row in non root set table deletes it",
+			testData: updateNoRootUnreferencedRowTestData(),
+		},
 		{
 			// corner case
 			// adding a weak reference to a nonexistent row is a noop
@@ -1951,6 +1958,44 @@ func insertNoRootUnreferencedRowTestData() testData {
 	}
 }
 
+func updateNoRootUnreferencedRowTestData() testData {
+	return testData{
+		existingModels: []model.Model{
+			&Child{
+				UUID: "child",
+			},
+			&Grandchild{
+				UUID: "grandchild",
+			},
+		},
+		// an existing child that is not referenced from anywhere is updated
+		updatedModels: []model.Model{
+			&Child{
+				UUID:                        "child",
+				WeakAtomicOptionalReference: ptr("grandchild"),
+			},
+			&Grandchild{
+				UUID: "grandchild",
+			},
+		},
+		// but is deleted since the table is not part of the root set
+		finalModels: []model.Model{
+			&Grandchild{
+				UUID: "grandchild",
+			},
+		},
+		wantUpdatedReferences: database.References{
+			database.ReferenceSpec{
+				ToTable:    "Grandchild",
+				FromTable:  "Child",
+				FromColumn: "weak_atomic_optional_reference",
+			}: database.Reference{
+				"grandchild": nil,
+			},
+		},
+	}
+}
+
 func weakReferenceToNonExistentRowTestData() testData {
 	return testData{
 		existingModels: []model.Model{
@@ -258,6 +258,11 @@ func (u *ModelUpdates) addInsertOperation(dbModel model.DatabaseModel, table, uu
 		return err
 	}
 
+	err = validateColumns(dbModel, table, mapperInfo, op.Row)
+	if err != nil {
+		return err
+	}
+
 	err = mapperInfo.SetField("_uuid", uuid)
 	if err != nil {
 		return err
@@ -311,6 +316,11 @@ func (u *ModelUpdates) addUpdateOperation(dbModel model.DatabaseModel, table, uu
 		return nil
 	}
 
+	err = validateColumns(dbModel, table, newInfo, op.Row)
+	if err != nil {
+		return err
+	}
+
 	newRow, err := m.NewRow(newInfo)
 	if err != nil {
 		return err
@@ -384,6 +394,9 @@ func (u *ModelUpdates) addMutateOperation(dbModel model.DatabaseModel, table, uu
 		}
 
 		newValue, diff := mutate(current, mutation.Mutator, nativeValue)
+		if err := ovsdb.ValidateValue(column, newValue); err != nil {
+			return err
+		}
 		if err := newInfo.SetField(mutation.Column, newValue); err != nil {
 			return err
 		}
@@ -461,6 +474,27 @@ func (u *ModelUpdates) addDeleteOperation(dbModel model.DatabaseModel, table, uu
 	return err
 }
 
+// validateColumns checks that the values of the provided columns of a model
+// satisfy the constraints of their schema
+func validateColumns(dbModel model.DatabaseModel, table string, info *mapper.Info, row ovsdb.Row) error {
+	schema := dbModel.Schema.Table(table)
+	for column := range row {
+		colSchema := schema.Column(column)
+		if _, ok := info.Metadata.Fields[column]; !ok || colSchema == nil {
+			// the model does not hold this column, ignore it
+			continue
+		}
+		value, err := info.FieldByColumn(column)
+		if err != nil {
+			return err
+		}
+		if err := ovsdb.ValidateValue(colSchema, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func updateModel(dbModel model.DatabaseModel, table string, info *mapper.Info, update, modify *ovsdb.Row) (bool, error) {
 	return updateOrModifyModel(dbModel, table, info, update, modify, false)
 }